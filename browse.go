@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diverdib/gator/internal/database"
+	"github.com/diverdib/gator/internal/htmltext"
+	"github.com/google/uuid"
+)
+
+func handlerBrowse(s *state, cmd command, user database.User) error {
+	usage := fmt.Errorf("usage: %s [limit] [--unread] [--feed <url|name>] [--since <date>] [--until <date>] [--author <name>] [--category <name>] [--tag <tag>] [--sort published|fetched] [--after <cursor>]", cmd.name)
+
+	fs := newFlagSet(cmd)
+	unread := fs.Bool("unread", false, "only show posts that haven't been read")
+	feedArg := fs.String("feed", "", "only show posts of this feed")
+	since := fs.String("since", "", "only show posts from this date on")
+	until := fs.String("until", "", "only show posts up to this date, including the day itself")
+	author := fs.String("author", "", "only show posts by this author")
+	category := fs.String("category", "", "only show posts in this category")
+	tag := fs.String("tag", "", "only show posts of feeds with this tag")
+	sortBy := fs.String("sort", "published", "sort by published or fetched date")
+	after := fs.String("after", "", "continue after this cursor")
+	args, err := parseArgs(fs, cmd.args)
+	if err != nil {
+		return fmt.Errorf("%w; %v", err, usage)
+	}
+	if len(args) > 1 {
+		return usage
+	}
+
+	limit := 2
+	if len(args) == 1 {
+		limit, err = strconv.Atoi(args[0])
+		if err != nil || limit < 1 {
+			return fmt.Errorf("invalid limit %q, must be a positive number", args[0])
+		}
+	}
+
+	if *sortBy != "published" && *sortBy != "fetched" {
+		return fmt.Errorf("invalid sort %q, must be published or fetched", *sortBy)
+	}
+
+	params := database.BrowsePostsParams{
+		SortByFetched: *sortBy == "fetched",
+		UserID:        user.ID,
+		UnreadOnly:    *unread,
+		Limit:         int32(limit),
+	}
+
+	if *feedArg != "" {
+		feed, err := resolveFollowedFeed(s, user, *feedArg)
+		if err != nil {
+			return err
+		}
+		params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+	if *since != "" {
+		t, err := parseDateArg(*since)
+		if err != nil {
+			return err
+		}
+		params.Since = sql.NullTime{Time: t, Valid: true}
+	}
+	if *until != "" {
+		t, err := parseDateArg(*until)
+		if err != nil {
+			return err
+		}
+		// A bare day includes that whole day, posts are compared with <
+		if _, err := time.Parse(time.DateOnly, *until); err == nil {
+			t = t.AddDate(0, 0, 1)
+		}
+		params.Until = sql.NullTime{Time: t, Valid: true}
+	}
+	if *author != "" {
+		params.Author = sql.NullString{String: *author, Valid: true}
+	}
+	if *category != "" {
+		params.Category = sql.NullString{String: *category, Valid: true}
+	}
+	if *tag != "" {
+		// Tags are stored the way cleanTags writes them
+		tags := cleanTags([]string{*tag})
+		if len(tags) == 0 {
+			return fmt.Errorf("invalid tag %q", *tag)
+		}
+		params.Tag = sql.NullString{String: tags[0], Valid: true}
+	}
+	if *after != "" {
+		t, id, err := decodeCursor(*after, *sortBy)
+		if err != nil {
+			return err
+		}
+		params.AfterTime = sql.NullTime{Time: t, Valid: true}
+		params.AfterID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	posts, err := s.db.BrowsePosts(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("could not get posts: %w", err)
+	}
+
+	fmt.Printf("Found %d posts for user %s:\n", len(posts), user.Name)
+	for _, post := range posts {
+		marker := "*"
+		if post.Read {
+			marker = " "
+		}
+		fmt.Printf("%s %s from %s (%s)\n", marker, post.SortTime.Format("Mon Jan _2"), htmltext.Printable(post.Title), htmltext.Printable(post.FeedName))
+		fmt.Printf("--- %s ---\n", post.Url)
+		fmt.Printf("ID: %s\n", post.ID)
+		if post.EditedAt.Valid {
+			fmt.Printf("Edited: %s\n", post.EditedAt.Time.Format("Mon Jan _2 15:04"))
+		}
+		if post.Author.Valid {
+			fmt.Printf("Author: %s\n", htmltext.Printable(post.Author.String))
+		}
+		if len(post.Categories) > 0 {
+			fmt.Printf("Categories: %s\n", strings.Join(post.Categories, ", "))
+		}
+		fmt.Printf("Description: %v\n\n", htmltext.Render(post.Description.String))
+	}
+
+	// A full page means there may be more, hand out a cursor for it
+	if len(posts) == limit {
+		last := posts[len(posts)-1]
+		fmt.Printf("More posts: %s --after %s\n", cmd.name, encodeCursor(last.SortTime, last.ID, *sortBy))
+	}
+	return nil
+}
+
+// resolveFollowedFeed finds a feed by URL or, failing that, by the name of
+// one of the feeds the user follows.
+func resolveFollowedFeed(s *state, user database.User, urlOrName string) (database.Feed, error) {
+	feed, err := getFeedByURL(s, urlOrName)
+	if err == nil {
+		return feed, nil
+	}
+
+	feeds, err := s.db.GetFollowedFeeds(context.Background(), user.ID)
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("could not get feeds for user %s: %w", user.Name, err)
+	}
+	var matches []database.Feed
+	for _, f := range feeds {
+		if strings.EqualFold(f.Name, urlOrName) {
+			matches = append(matches, f)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return database.Feed{}, fmt.Errorf("you don't follow a feed with URL or name %q", urlOrName)
+	case 1:
+		return matches[0], nil
+	default:
+		return database.Feed{}, fmt.Errorf("%d followed feeds are named %q, use the URL instead", len(matches), urlOrName)
+	}
+}
+
+// encodeCursor packs the sort key of the last post on a page into an
+// opaque token. The sort order is included so a cursor can't be reused
+// with a different --sort.
+func encodeCursor(sortTime time.Time, id uuid.UUID, sortBy string) string {
+	raw := fmt.Sprintf("%s:%d:%s", sortBy, sortTime.UnixNano(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor, sortBy string) (time.Time, uuid.UUID, error) {
+	invalid := fmt.Errorf("invalid cursor %q", cursor)
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.UUID{}, invalid
+	}
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 {
+		return time.Time{}, uuid.UUID{}, invalid
+	}
+	if parts[0] != sortBy {
+		return time.Time{}, uuid.UUID{}, fmt.Errorf("cursor was made for --sort %s", parts[0])
+	}
+	nanos, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return time.Time{}, uuid.UUID{}, invalid
+	}
+	id, err := uuid.Parse(parts[2])
+	if err != nil {
+		return time.Time{}, uuid.UUID{}, invalid
+	}
+	return time.Unix(0, nanos).UTC(), id, nil
+}
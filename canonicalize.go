@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diverdib/gator/internal/database"
+	"github.com/diverdib/gator/internal/urlnorm"
+	"github.com/google/uuid"
+)
+
+// getFeedByURL looks a feed up by any spelling of its URL, or of a URL it
+// was known by before it moved.
+func getFeedByURL(s *state, feedURL string) (database.Feed, error) {
+	return s.db.GetFeedByUrl(context.Background(), database.GetFeedByUrlParams{
+		Url:    feedURL,
+		UrlKey: urlnorm.Key(feedURL),
+	})
+}
+
+// canonicalURL returns the normalized form of a URL, or the URL as is if
+// it can't be normalized, like the relative links some feeds contain.
+func canonicalURL(raw string) string {
+	normalized, err := urlnorm.Normalize(raw)
+	if err != nil {
+		return raw
+	}
+	return normalized
+}
+
+// canonicalizeStats counts what a canonicalize run changed.
+type canonicalizeStats struct {
+	feeds       int
+	feedsMerged int
+	posts       int
+	postsMerged int
+}
+
+// handlerCanonicalize rewrites stored feed and post URLs into their
+// canonical form and merges the feeds and posts that turn out to be the
+// same. Feeds added before URLs were normalized need this once. Each group
+// of feeds is merged in its own transaction, so an interrupted run leaves
+// nothing half moved and can simply be run again.
+func handlerCanonicalize(s *state, cmd command) error {
+	if len(cmd.args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.name)
+	}
+
+	feeds, err := s.db.ListFeedsOldestFirst(context.Background())
+	if err != nil {
+		return fmt.Errorf("could not get feeds: %w", err)
+	}
+
+	// Feeds are listed oldest first, so the oldest of a group survives
+	var keys []string
+	groups := make(map[string][]database.Feed)
+	for _, feed := range feeds {
+		key := urlnorm.Key(feed.Url)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], feed)
+	}
+
+	var stats canonicalizeStats
+	for _, key := range keys {
+		// Counted on a copy so a rolled back group isn't reported
+		groupStats := stats
+		err := withTx(s, func(tx *state) error {
+			return canonicalizeFeeds(tx, key, groups[key], &groupStats)
+		})
+		if err != nil {
+			return err
+		}
+		stats = groupStats
+	}
+
+	aliases, err := s.db.ListFeedAliases(context.Background())
+	if err != nil {
+		return fmt.Errorf("could not get feed aliases: %w", err)
+	}
+	for _, alias := range aliases {
+		key := urlnorm.Key(alias.Url)
+		if key == alias.UrlKey {
+			continue
+		}
+		err := s.db.SetFeedAliasKey(context.Background(), database.SetFeedAliasKeyParams{
+			Url:    alias.Url,
+			UrlKey: key,
+		})
+		if err != nil {
+			return fmt.Errorf("could not update alias %s: %w", alias.Url, err)
+		}
+	}
+
+	fmt.Printf("Canonicalized %d feed and %d post URLs, merged %d duplicate feeds and %d duplicate posts\n",
+		stats.feeds, stats.posts, stats.feedsMerged, stats.postsMerged)
+	return nil
+}
+
+// canonicalizeFeeds merges a group of feeds with the same URL key into the
+// first one and normalizes the URLs of that feed and its posts.
+func canonicalizeFeeds(s *state, key string, group []database.Feed, stats *canonicalizeStats) error {
+	kept := group[0]
+	for _, dup := range group[1:] {
+		if err := mergeFeed(s, dup, kept, stats); err != nil {
+			return err
+		}
+	}
+
+	feedURL := canonicalURL(kept.Url)
+	if feedURL != kept.Url || key != kept.UrlKey {
+		err := s.db.SetFeedUrl(context.Background(), database.SetFeedUrlParams{
+			ID:        kept.ID,
+			Url:       feedURL,
+			UrlKey:    key,
+			UpdatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("could not update URL of feed %s: %w", kept.Url, err)
+		}
+		stats.feeds++
+	}
+
+	return canonicalizePosts(s, kept.ID, kept.ID, stats)
+}
+
+// mergeFeed moves the follows, aliases and posts of a duplicate feed to the
+// feed that is kept and deletes the duplicate. Users who follow both keep
+// a single follow with the tags of both, and posts both feeds have are
+// merged. Run it in a transaction, it takes several statements.
+func mergeFeed(s *state, dup, kept database.Feed, stats *canonicalizeStats) error {
+	err := s.db.MergeFollowTags(context.Background(), database.MergeFollowTagsParams{
+		ToFeedID:   kept.ID,
+		FromFeedID: dup.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("could not merge tags of feed %s: %w", dup.Url, err)
+	}
+
+	err = s.db.MoveFeedFollows(context.Background(), database.MoveFeedFollowsParams{
+		ToFeedID:   kept.ID,
+		FromFeedID: dup.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("could not move follows of feed %s: %w", dup.Url, err)
+	}
+
+	err = s.db.MoveFeedAliases(context.Background(), database.MoveFeedAliasesParams{
+		ToFeedID:   kept.ID,
+		FromFeedID: dup.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("could not move aliases of feed %s: %w", dup.Url, err)
+	}
+
+	// A URL that doesn't share the key of the kept feed has to stay findable
+	if dupKey := urlnorm.Key(dup.Url); dupKey != urlnorm.Key(kept.Url) {
+		err = s.db.AddFeedAlias(context.Background(), database.AddFeedAliasParams{
+			Url:       dup.Url,
+			UrlKey:    dupKey,
+			FeedID:    kept.ID,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("could not keep %s as an alias: %w", dup.Url, err)
+		}
+	}
+
+	if err := canonicalizePosts(s, dup.ID, kept.ID, stats); err != nil {
+		return err
+	}
+
+	// Whatever is left are follows and posts the kept feed already has
+	err = s.db.DeleteFeed(context.Background(), dup.ID)
+	if err != nil {
+		return fmt.Errorf("could not delete feed %s: %w", dup.Url, err)
+	}
+	fmt.Printf("Merged feed %s into %s\n", dup.Url, kept.Url)
+	stats.feedsMerged++
+	return nil
+}
+
+// canonicalizePosts normalizes the URLs of the posts of a feed and moves
+// them to another feed, or to the same one. Dedupe keys come from the
+// links as the feed has them, so they don't change here. A post whose key
+// is already taken in the target feed is a duplicate: its read states
+// and revisions move over and it's deleted.
+func canonicalizePosts(s *state, fromFeedID, toFeedID uuid.UUID, stats *canonicalizeStats) error {
+	posts, err := s.db.ListFeedPostUrls(context.Background(), fromFeedID)
+	if err != nil {
+		return fmt.Errorf("could not get posts: %w", err)
+	}
+
+	for _, post := range posts {
+		postURL := canonicalURL(post.Url)
+		if fromFeedID == toFeedID && postURL == post.Url {
+			continue
+		}
+
+		if fromFeedID != toFeedID {
+			existing, err := s.db.GetPostByDedupeKey(context.Background(), database.GetPostByDedupeKeyParams{
+				FeedID:    toFeedID,
+				DedupeKey: post.DedupeKey,
+			})
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("could not get post %q: %w", post.Title, err)
+			}
+			if err == nil {
+				err = s.db.MergePostStates(context.Background(), database.MergePostStatesParams{
+					ToPostID:   existing.ID,
+					FromPostID: post.ID,
+				})
+				if err != nil {
+					return fmt.Errorf("could not merge read state of post %q: %w", post.Title, err)
+				}
+				err = s.db.MovePostRevisions(context.Background(), database.MovePostRevisionsParams{
+					ToPostID:   existing.ID,
+					FromPostID: post.ID,
+				})
+				if err != nil {
+					return fmt.Errorf("could not move revisions of post %q: %w", post.Title, err)
+				}
+				err = s.db.DeletePost(context.Background(), post.ID)
+				if err != nil {
+					return fmt.Errorf("could not delete duplicate post %q: %w", post.Title, err)
+				}
+				stats.postsMerged++
+				continue
+			}
+		}
+
+		err = s.db.MovePost(context.Background(), database.MovePostParams{
+			ID:     post.ID,
+			FeedID: toFeedID,
+			Url:    postURL,
+		})
+		if err != nil {
+			return fmt.Errorf("could not update post %q: %w", post.Title, err)
+		}
+		if postURL != post.Url {
+			stats.posts++
+		}
+	}
+	return nil
+}
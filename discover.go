@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// feedCandidate is a feed found while looking for the feeds of a website.
+// Feed is only set for candidates that were already fetched.
+type feedCandidate struct {
+	URL   string
+	Title string
+	Feed  *ParsedFeed
+}
+
+// feedMediaTypes are the link types that announce a feed in an HTML page.
+var feedMediaTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+}
+
+// commonFeedPaths are tried when a page doesn't announce any feeds.
+var commonFeedPaths = []string{"/feed", "/rss.xml", "/atom.xml", "/index.xml"}
+
+// maxDocumentSize caps how much of a page is read during discovery
+const maxDocumentSize = 10 << 20
+
+// document is a page or feed fetched during discovery.
+type document struct {
+	data        []byte
+	contentType string
+	// base is where the document was served from after all redirects,
+	// relative links in it resolve against it
+	base *url.URL
+	// url is the address to store for it: the one that was asked for,
+	// unless the document moved there for good with permanent redirects
+	url string
+}
+
+// discoverFeeds returns the feeds behind a URL. A feed URL is returned as
+// is, for an HTML page the feeds it links to are returned, or, when it
+// doesn't link to any, the feeds found at the usual paths of the site.
+func discoverFeeds(ctx context.Context, pageURL string) ([]feedCandidate, error) {
+	page, err := fetchDocument(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if feed, err := parseFeed(page.contentType, page.data); err == nil {
+		return []feedCandidate{{URL: page.url, Title: feed.Title, Feed: feed}}, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(page.contentType)
+	if mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("%s is neither a feed nor a web page (%s)", pageURL, mediaType)
+	}
+
+	candidates := feedLinks(page.data, page.base)
+	if len(candidates) > 0 {
+		return candidates, nil
+	}
+
+	for _, path := range commonFeedPaths {
+		guess := page.base.ResolveReference(&url.URL{Path: path}).String()
+		doc, err := fetchDocument(ctx, guess)
+		if err != nil {
+			continue
+		}
+		feed, err := parseFeed(doc.contentType, doc.data)
+		if err != nil || containsCandidate(candidates, doc.url) {
+			continue
+		}
+		candidates = append(candidates, feedCandidate{URL: doc.url, Title: feed.Title, Feed: feed})
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no feeds found at %s", pageURL)
+	}
+	return candidates, nil
+}
+
+// loadCandidate fetches and parses a candidate that was only linked to,
+// rejecting links that don't lead to a feed.
+func loadCandidate(ctx context.Context, c feedCandidate) (feedCandidate, error) {
+	if c.Feed != nil {
+		return c, nil
+	}
+
+	doc, err := fetchDocument(ctx, c.URL)
+	if err != nil {
+		return feedCandidate{}, err
+	}
+	feed, err := parseFeed(doc.contentType, doc.data)
+	if err != nil {
+		return feedCandidate{}, fmt.Errorf("%s is not a feed: %w", c.URL, err)
+	}
+	return feedCandidate{URL: doc.url, Title: feed.Title, Feed: feed}, nil
+}
+
+// fetchDocument downloads a page or feed for discovery. Like fetchFeed it
+// only follows a URL to where it moved permanently, temporary redirects
+// keep the URL that was asked for.
+func fetchDocument(ctx context.Context, pageURL string) (*document, error) {
+	result, resp, err := getDocument(ctx, pageURL, "", "")
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return nil, fmt.Errorf("could not fetch %s: status code %d", pageURL, statusErr.StatusCode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &document{
+		data:        data,
+		contentType: resp.Header.Get("Content-Type"),
+		base:        resp.Request.URL,
+		url:         pageURL,
+	}
+	if result.PermanentURL != "" {
+		doc.url = result.PermanentURL
+	}
+	return doc, nil
+}
+
+// feedLinks returns the feeds announced by <link rel="alternate"> tags in
+// an HTML page, resolving relative URLs against the page or its <base>.
+func feedLinks(data []byte, base *url.URL) []feedCandidate {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+
+	var candidates []feedCandidate
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Base:
+				if href, err := base.Parse(attr(n, "href")); err == nil && attr(n, "href") != "" {
+					base = href
+				}
+			case atom.Link:
+				if isAlternate(attr(n, "rel")) && feedMediaTypes[strings.ToLower(strings.TrimSpace(attr(n, "type")))] {
+					href, err := base.Parse(strings.TrimSpace(attr(n, "href")))
+					if err == nil && attr(n, "href") != "" && !containsCandidate(candidates, href.String()) {
+						candidates = append(candidates, feedCandidate{
+							URL:   href.String(),
+							Title: strings.TrimSpace(attr(n, "title")),
+						})
+					}
+				}
+			case atom.Body:
+				// Feed links belong in the head, don't walk the whole page
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return candidates
+}
+
+// isAlternate reports whether a rel attribute contains "alternate". Rel
+// holds a space separated list, like "alternate feed".
+func isAlternate(rel string) bool {
+	for _, r := range strings.Fields(rel) {
+		if strings.EqualFold(r, "alternate") {
+			return true
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func containsCandidate(candidates []feedCandidate, feedURL string) bool {
+	for _, c := range candidates {
+		if c.URL == feedURL {
+			return true
+		}
+	}
+	return false
+}
+
+// pickFeed asks the user which of several discovered feeds to use.
+func pickFeed(candidates []feedCandidate) (feedCandidate, error) {
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	fmt.Println("Found several feeds:")
+	for i, c := range candidates {
+		if c.Title != "" {
+			fmt.Printf("%d) %s (%s)\n", i+1, c.Title, c.URL)
+		} else {
+			fmt.Printf("%d) %s\n", i+1, c.URL)
+		}
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("Pick a feed [1-%d]: ", len(candidates))
+		line, err := reader.ReadString('\n')
+		if n, convErr := strconv.Atoi(strings.TrimSpace(line)); convErr == nil && n >= 1 && n <= len(candidates) {
+			return candidates[n-1], nil
+		}
+		if err != nil {
+			return feedCandidate{}, fmt.Errorf("no feed picked, pass one of the feed URLs instead")
+		}
+		fmt.Println("Please enter one of the numbers above.")
+	}
+}
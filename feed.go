@@ -0,0 +1,765 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// ParsedFeed is the format-independent representation of a fetched feed.
+// Every supported format is normalized into it before posts are stored.
+type ParsedFeed struct {
+	Title       string
+	Link        string
+	Description string
+	Language    string
+	Image       string
+	Items       []FeedItem
+
+	// Scheduling hints, only RSS carries them
+	TTL       time.Duration
+	SkipHours []int
+	SkipDays  []time.Weekday
+}
+
+// FeedItem is a single post in a ParsedFeed. Description is the summary
+// shown in listings, Content the full article when the feed carries it.
+type FeedItem struct {
+	Title       string
+	Link        string
+	Description string
+	PubDate     string
+	Content     string
+	Author      string
+	Categories  []string
+	GUID        string
+	Comments    string
+	Enclosure   Enclosure
+}
+
+// Enclosure is a media file attached to an item, like a podcast episode.
+type Enclosure struct {
+	URL    string
+	Type   string
+	Length int64
+}
+
+type RSSFeed struct {
+	Channel struct {
+		Title       string       `xml:"title"`
+		Links       []RSSElement `xml:"link"`
+		Description string       `xml:"description"`
+		Language    string       `xml:"language"`
+		Images      []RSSImage   `xml:"image"`
+		TTL         string       `xml:"ttl"`
+		SkipHours   []string     `xml:"skipHours>hour"`
+		SkipDays    []string     `xml:"skipDays>day"`
+		Item        []RSSItem    `xml:"item"`
+	} `xml:"channel"`
+}
+
+// RSSImage matches both the RSS <image> and the <itunes:image href="...">
+// of podcasts, separate fields for the two would conflict in encoding/xml.
+type RSSImage struct {
+	URL  string `xml:"url"`
+	Href string `xml:"href,attr"`
+}
+type RSSItem struct {
+	Title       string       `xml:"title"`
+	Links       []RSSElement `xml:"link"`
+	Description string       `xml:"description"`
+	PubDate     string       `xml:"pubDate"`
+	Content     string       `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Creator     string       `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Date        string       `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Authors     []RSSElement `xml:"author"`
+	Categories  []RSSElement `xml:"category"`
+	GUID        RSSGUID      `xml:"guid"`
+	Comments    string       `xml:"comments"`
+	Enclosure   RSSEnclosure `xml:"enclosure"`
+}
+
+// RSSElement is an element whose name extensions reuse, like <atom:link>
+// next to <link> or <itunes:author> next to <author>. encoding/xml matches
+// a name in any namespace, so all of them are collected and rssText picks
+// the RSS ones.
+type RSSElement struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// rssText returns the trimmed values of the elements without a namespace,
+// which is where RSS 2.0 puts its own, skipping empty ones.
+func rssText(elements []RSSElement) []string {
+	var values []string
+	for _, e := range elements {
+		value := strings.TrimSpace(e.Value)
+		if e.XMLName.Space == "" && value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// firstRSSText returns the first value rssText finds, or "".
+func firstRSSText(elements []RSSElement) string {
+	if values := rssText(elements); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// RSSGUID identifies an item. Unless isPermaLink is "false" it is also
+// the URL of the item.
+type RSSGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink string `xml:"isPermaLink,attr"`
+}
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// JSONFeed is a JSON Feed 1.0/1.1 document (https://jsonfeed.org).
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Language    string         `json:"language"`
+	Icon        string         `json:"icon"`
+	Favicon     string         `json:"favicon"`
+	Items       []JSONFeedItem `json:"items"`
+}
+type JSONFeedItem struct {
+	ID            json.RawMessage      `json:"id"`
+	URL           string               `json:"url"`
+	ExternalURL   string               `json:"external_url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Tags          []string             `json:"tags"`
+	Author        *JSONFeedAuthor      `json:"author"`
+	Authors       []JSONFeedAuthor     `json:"authors"`
+	Attachments   []JSONFeedAttachment `json:"attachments"`
+}
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+type JSONFeedAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	SizeInBytes int64  `json:"size_in_bytes"`
+}
+
+// AtomFeed is an Atom 1.0 (RFC 4287) document.
+type AtomFeed struct {
+	Base     string      `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+	Lang     string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Title    AtomText    `xml:"title"`
+	Subtitle AtomText    `xml:"subtitle"`
+	Logo     string      `xml:"logo"`
+	Icon     string      `xml:"icon"`
+	Links    []AtomLink  `xml:"link"`
+	Entries  []AtomEntry `xml:"entry"`
+}
+type AtomEntry struct {
+	Base       string         `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+	ID         string         `xml:"id"`
+	Title      AtomText       `xml:"title"`
+	Links      []AtomLink     `xml:"link"`
+	Summary    AtomText       `xml:"summary"`
+	Content    AtomText       `xml:"content"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Authors    []AtomPerson   `xml:"author"`
+	Categories []AtomCategory `xml:"category"`
+}
+type AtomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+// AtomCategory has a machine-readable term and an optional display label.
+type AtomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+// AtomText is an Atom text construct. Its type attribute decides whether
+// the payload is character data ("text", "html") or inline markup ("xhtml").
+type AtomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t AtomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+// alternateLink returns the href of the rel="alternate" link, which is
+// also the meaning of a link without a rel attribute.
+func alternateLink(links []AtomLink) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+// linkByRel returns the first link with the given rel attribute.
+func linkByRel(links []AtomLink, rel string) (AtomLink, bool) {
+	for _, l := range links {
+		if l.Rel == rel {
+			return l, true
+		}
+	}
+	return AtomLink{}, false
+}
+
+// fetchResult is the outcome of a successful fetchFeed call. When the
+// server answers 304 Not Modified, Feed is nil and NotModified is set.
+type fetchResult struct {
+	Feed         *ParsedFeed
+	StatusCode   int
+	NotModified  bool
+	ETag         string
+	LastModified string
+
+	// Caching hints from the Cache-Control and Retry-After headers
+	MaxAge     time.Duration
+	RetryAfter time.Duration
+
+	// Redirects followed to reach the document, and the URL the feed has
+	// permanently moved to if the chain starts with 301/308 redirects
+	Redirects    []redirect
+	PermanentURL string
+}
+
+// redirect is a single hop in a redirect chain.
+type redirect struct {
+	From       string
+	To         string
+	StatusCode int
+}
+
+// maxRedirects matches the limit of the default http.Client
+const maxRedirects = 10
+
+// statusError is returned by fetchFeed when the server answers with a
+// status other than 200 or 304.
+type statusError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("failed to fetch feed: status code %d", e.StatusCode)
+}
+
+// fetchFeed downloads and parses a feed. If etag or lastModified are set
+// from a previous fetch the request is made conditional.
+func fetchFeed(ctx context.Context, feedURL, etag, lastModified string) (*fetchResult, error) {
+	result, resp, err := getDocument(ctx, feedURL, etag, lastModified)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if result.NotModified {
+		return result, nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	feed, err := parseFeed(resp.Header.Get("Content-Type"), data)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("Feed fetched successfully:", feed.Title)
+
+	result.Feed = feed
+	return result, nil
+}
+
+// getDocument requests a feed or a page, recording the redirects on the
+// way. The response is returned for 200 and 304 answers, other statuses
+// are a statusError. The caller closes the body of the response.
+func getDocument(ctx context.Context, docURL, etag, lastModified string) (*fetchResult, *http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, docURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Set the User-Agent header
+	req.Header.Set("User-Agent", "gator")
+
+	// Only transfer the document if it changed since the last fetch
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	// Make the HTTP request, recording every redirect on the way
+	var redirects []redirect
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			redirects = append(redirects, redirect{
+				From:       via[len(via)-1].URL.String(),
+				To:         req.URL.String(),
+				StatusCode: req.Response.StatusCode,
+			})
+			return nil
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &fetchResult{
+		StatusCode:   resp.StatusCode,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		MaxAge:       parseMaxAge(resp.Header.Get("Cache-Control")),
+		RetryAfter:   parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		Redirects:    redirects,
+		PermanentURL: permanentURL(redirects),
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		result.NotModified = true
+		// A 304 may omit the validators, keep the ones we sent
+		if result.ETag == "" {
+			result.ETag = etag
+		}
+		if result.LastModified == "" {
+			result.LastModified = lastModified
+		}
+		return result, resp, nil
+	}
+
+	// Check for non-200 status codes
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, nil, &statusError{StatusCode: resp.StatusCode, RetryAfter: result.RetryAfter}
+	}
+	return result, resp, nil
+}
+
+// unescapeRSS decodes the HTML entities many RSS feeds double-escape.
+// Atom and JSON Feed say whether text is HTML, entities left in it after
+// parsing are meant to be shown as they are.
+func unescapeRSS(feed *ParsedFeed) {
+	// Unescape the top level fields
+	feed.Title = html.UnescapeString(feed.Title)
+	feed.Link = html.UnescapeString(feed.Link)
+	feed.Description = html.UnescapeString(feed.Description)
+	feed.Image = html.UnescapeString(feed.Image)
+
+	// Unescape each Item's fields
+	for i := range feed.Items {
+		feed.Items[i].Title = html.UnescapeString(feed.Items[i].Title)
+		feed.Items[i].Link = html.UnescapeString(feed.Items[i].Link)
+		feed.Items[i].Description = html.UnescapeString(feed.Items[i].Description)
+		feed.Items[i].Content = html.UnescapeString(feed.Items[i].Content)
+		feed.Items[i].PubDate = html.UnescapeString(feed.Items[i].PubDate)
+		feed.Items[i].Author = html.UnescapeString(feed.Items[i].Author)
+		for j, category := range feed.Items[i].Categories {
+			feed.Items[i].Categories[j] = html.UnescapeString(category)
+		}
+	}
+}
+
+// permanentURL returns the last URL reached through an unbroken run of
+// permanent redirects from the start of the chain. A temporary redirect
+// ends the run, since only the hops before it are known to be stable.
+func permanentURL(redirects []redirect) string {
+	moved := ""
+	for _, r := range redirects {
+		if r.StatusCode != http.StatusMovedPermanently && r.StatusCode != http.StatusPermanentRedirect {
+			break
+		}
+		moved = r.To
+	}
+	return moved
+}
+
+// parseFeed detects the document format from the Content-Type header or,
+// failing that, from the body itself, and normalizes it into a ParsedFeed.
+func parseFeed(contentType string, data []byte) (*ParsedFeed, error) {
+	if isJSONFeed(contentType, data) {
+		return parseJSONFeed(data)
+	}
+
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "rss":
+		return parseRSS(data)
+	case "feed":
+		return parseAtom(data)
+	default:
+		return nil, fmt.Errorf("unsupported feed format: root element <%s>", root)
+	}
+}
+
+// isJSONFeed reports whether a response should be decoded as JSON Feed.
+// Many servers send feed.json as text/plain, so the body is sniffed too.
+func isJSONFeed(contentType string, data []byte) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if mediaType == "application/feed+json" || mediaType == "application/json" {
+		return true
+	}
+	trimmed := bytes.TrimLeft(data, " \t\r\n\ufeff")
+	return bytes.HasPrefix(trimmed, []byte("{"))
+}
+
+// rootElement returns the local name of the first element in an XML document.
+func rootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	for {
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return "", errors.New("document has no root element")
+		}
+		if err != nil {
+			return "", err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// decodeXML unmarshals an XML document like xml.Unmarshal, converting
+// encodings other than UTF-8, such as the ISO-8859-1 and windows-1252 many
+// older feeds declare.
+func decodeXML(data []byte, v any) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	return decoder.Decode(v)
+}
+
+func parseRSS(data []byte) (*ParsedFeed, error) {
+	var rss RSSFeed
+	err := decodeXML(data, &rss)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &ParsedFeed{
+		Title:       rss.Channel.Title,
+		Link:        firstRSSText(rss.Channel.Links),
+		Description: strings.TrimSpace(rss.Channel.Description),
+		Language:    strings.TrimSpace(rss.Channel.Language),
+	}
+	for _, image := range rss.Channel.Images {
+		if href := strings.TrimSpace(image.URL); href != "" {
+			feed.Image = href
+			break
+		}
+		// Podcasts often only have the artwork required by Apple Podcasts
+		if feed.Image == "" {
+			feed.Image = strings.TrimSpace(image.Href)
+		}
+	}
+
+	// <ttl> is in minutes, <hour> is 0-23 GMT, <day> is an English weekday
+	if ttl, err := strconv.Atoi(strings.TrimSpace(rss.Channel.TTL)); err == nil && ttl > 0 {
+		feed.TTL = time.Duration(ttl) * time.Minute
+	}
+	for _, h := range rss.Channel.SkipHours {
+		if hour, err := strconv.Atoi(strings.TrimSpace(h)); err == nil && hour >= 0 && hour <= 23 {
+			feed.SkipHours = append(feed.SkipHours, hour)
+		}
+	}
+	for _, d := range rss.Channel.SkipDays {
+		for wd := time.Sunday; wd <= time.Saturday; wd++ {
+			if strings.EqualFold(strings.TrimSpace(d), wd.String()) {
+				feed.SkipDays = append(feed.SkipDays, wd)
+			}
+		}
+	}
+
+	for _, item := range rss.Channel.Item {
+		guid := strings.TrimSpace(item.GUID.Value)
+		link := firstRSSText(item.Links)
+		if link == "" && guid != "" && !strings.EqualFold(item.GUID.IsPermaLink, "false") {
+			link = guid
+		}
+		// <author> is meant to hold an email address, dc:creator a name
+		author := strings.TrimSpace(item.Creator)
+		if author == "" {
+			author = firstRSSText(item.Authors)
+		}
+		// Feeds built with the Dublin Core module date items with dc:date
+		pubDate := strings.TrimSpace(item.PubDate)
+		if pubDate == "" {
+			pubDate = strings.TrimSpace(item.Date)
+		}
+		length, _ := strconv.ParseInt(strings.TrimSpace(item.Enclosure.Length), 10, 64)
+		feed.Items = append(feed.Items, FeedItem{
+			Title:       item.Title,
+			Link:        link,
+			Description: item.Description,
+			PubDate:     pubDate,
+			Content:     strings.TrimSpace(item.Content),
+			Author:      author,
+			Categories:  cleanCategories(rssText(item.Categories)),
+			GUID:        guid,
+			Comments:    strings.TrimSpace(item.Comments),
+			Enclosure: Enclosure{
+				URL:    strings.TrimSpace(item.Enclosure.URL),
+				Type:   strings.TrimSpace(item.Enclosure.Type),
+				Length: length,
+			},
+		})
+	}
+	unescapeRSS(feed)
+	return feed, nil
+}
+
+func parseAtom(data []byte) (*ParsedFeed, error) {
+	var atom AtomFeed
+	err := decodeXML(data, &atom)
+	if err != nil {
+		return nil, err
+	}
+
+	// Relative links resolve against xml:base where the feed sets it, the
+	// rest against the feed URL once the feed is stored
+	feed := &ParsedFeed{
+		Title:       atom.Title.String(),
+		Link:        resolveReference(atom.Base, alternateLink(atom.Links)),
+		Description: atom.Subtitle.String(),
+		Language:    strings.TrimSpace(atom.Lang),
+		Image:       strings.TrimSpace(atom.Logo),
+	}
+	if feed.Image == "" {
+		feed.Image = strings.TrimSpace(atom.Icon)
+	}
+	for _, entry := range atom.Entries {
+		// Entries without a summary only have the full content to show
+		description := entry.Summary.String()
+		if description == "" {
+			description = entry.Content.String()
+		}
+		// An entry must have <updated>, <published> is optional
+		pubDate := entry.Published
+		if pubDate == "" {
+			pubDate = entry.Updated
+		}
+		var authors []string
+		for _, a := range entry.Authors {
+			if name := strings.TrimSpace(a.Name); name != "" {
+				authors = append(authors, name)
+			}
+		}
+		var categories []string
+		for _, c := range entry.Categories {
+			if c.Label != "" {
+				categories = append(categories, c.Label)
+			} else {
+				categories = append(categories, c.Term)
+			}
+		}
+		base := atom.Base
+		if entry.Base != "" {
+			base = resolveReference(atom.Base, entry.Base)
+		}
+		item := FeedItem{
+			Title:       entry.Title.String(),
+			Link:        resolveReference(base, alternateLink(entry.Links)),
+			Description: description,
+			PubDate:     strings.TrimSpace(pubDate),
+			Content:     entry.Content.String(),
+			Author:      strings.Join(authors, ", "),
+			Categories:  cleanCategories(categories),
+			GUID:        strings.TrimSpace(entry.ID),
+		}
+		if replies, ok := linkByRel(entry.Links, "replies"); ok {
+			item.Comments = replies.Href
+		}
+		if enclosure, ok := linkByRel(entry.Links, "enclosure"); ok {
+			length, _ := strconv.ParseInt(strings.TrimSpace(enclosure.Length), 10, 64)
+			item.Enclosure = Enclosure{URL: enclosure.Href, Type: enclosure.Type, Length: length}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+func parseJSONFeed(data []byte) (*ParsedFeed, error) {
+	var jf JSONFeed
+	err := json.Unmarshal(bytes.TrimPrefix(data, []byte("\ufeff")), &jf)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON feed: %w", err)
+	}
+	if !strings.HasPrefix(jf.Version, "https://jsonfeed.org/version/") {
+		return nil, fmt.Errorf("unsupported JSON feed version %q", jf.Version)
+	}
+
+	feed := &ParsedFeed{
+		Title:       jf.Title,
+		Link:        jf.HomePageURL,
+		Description: jf.Description,
+		Language:    jf.Language,
+		Image:       jf.Icon,
+	}
+	if feed.Image == "" {
+		feed.Image = jf.Favicon
+	}
+	for _, item := range jf.Items {
+		id := jsonFeedID(item.ID)
+		// url is optional, ids are often permalinks as well
+		link := item.URL
+		if link == "" {
+			link = item.ExternalURL
+		}
+		if link == "" && (strings.HasPrefix(id, "http://") || strings.HasPrefix(id, "https://")) {
+			link = id
+		}
+		content := item.ContentHTML
+		if content == "" {
+			content = item.ContentText
+		}
+		description := item.Summary
+		if description == "" {
+			description = content
+		}
+		pubDate := item.DatePublished
+		if pubDate == "" {
+			pubDate = item.DateModified
+		}
+		// Version 1.1 replaced author with a list of authors
+		var authors []string
+		for _, a := range item.Authors {
+			if a.Name != "" {
+				authors = append(authors, a.Name)
+			}
+		}
+		if len(authors) == 0 && item.Author != nil && item.Author.Name != "" {
+			authors = append(authors, item.Author.Name)
+		}
+		var enclosure Enclosure
+		if len(item.Attachments) > 0 {
+			a := item.Attachments[0]
+			enclosure = Enclosure{URL: a.URL, Type: a.MimeType, Length: a.SizeInBytes}
+		}
+		feed.Items = append(feed.Items, FeedItem{
+			Title:       item.Title,
+			Link:        link,
+			Description: description,
+			PubDate:     pubDate,
+			Content:     content,
+			Author:      strings.Join(authors, ", "),
+			Categories:  cleanCategories(item.Tags),
+			GUID:        id,
+			Enclosure:   enclosure,
+		})
+	}
+	return feed, nil
+}
+
+// jsonFeedID returns the id of a JSON Feed item as a string. The spec
+// requires a string, but some feeds use numbers, which are kept as written.
+func jsonFeedID(raw json.RawMessage) string {
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return id
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+	return ""
+}
+
+// cleanCategories trims categories and drops empty and repeated ones.
+func cleanCategories(categories []string) []string {
+	var clean []string
+	seen := make(map[string]bool)
+	for _, c := range categories {
+		c = strings.TrimSpace(c)
+		if c == "" || seen[strings.ToLower(c)] {
+			continue
+		}
+		seen[strings.ToLower(c)] = true
+		clean = append(clean, c)
+	}
+	return clean
+}
+
+// parseMaxAge returns the max-age directive of a Cache-Control header.
+func parseMaxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if !ok || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 0
+}
+
+// parseRetryAfter returns the delay requested by a Retry-After header,
+// which is either a number of seconds or an HTTP date.
+func parseRetryAfter(retryAfter string, now time.Time) time.Duration {
+	retryAfter = strings.TrimSpace(retryAfter)
+	if retryAfter == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(retryAfter); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(retryAfter); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
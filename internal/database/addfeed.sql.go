@@ -7,31 +7,43 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createFeed = `-- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, site_url, description, language, image_url, url_key)
 VALUES (
     $1, -- id
     $2, -- created_at
     $3, -- updated_at
     $4, -- name
     $5, -- url
-    $6  -- user_id
+    $6, -- user_id
+    $7, -- site_url
+    $8, -- description
+    $9, -- language
+    $10, -- image_url
+    $11 -- url_key
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, consecutive_failures, last_success_at, last_status, last_error, response_time_ms, item_count, enabled, disabled_reason, site_url, description, language, image_url, url_key, ttl_minutes, skip_hours, skip_days
 `
 
 type CreateFeedParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
-	Url       string
-	UserID    uuid.UUID
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Name        string
+	Url         string
+	UserID      uuid.UUID
+	SiteUrl     sql.NullString
+	Description sql.NullString
+	Language    sql.NullString
+	ImageUrl    sql.NullString
+	UrlKey      string
 }
 
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
@@ -42,6 +54,11 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		arg.Name,
 		arg.Url,
 		arg.UserID,
+		arg.SiteUrl,
+		arg.Description,
+		arg.Language,
+		arg.ImageUrl,
+		arg.UrlKey,
 	)
 	var i Feed
 	err := row.Scan(
@@ -52,6 +69,25 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.LastStatus,
+		&i.LastError,
+		&i.ResponseTimeMs,
+		&i.ItemCount,
+		&i.Enabled,
+		&i.DisabledReason,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.UrlKey,
+		&i.TtlMinutes,
+		pq.Array(&i.SkipHours),
+		pq.Array(&i.SkipDays),
 	)
 	return i, err
 }
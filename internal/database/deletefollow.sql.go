@@ -15,16 +15,19 @@ import (
 const deleteFeedFollow = `-- name: DeleteFeedFollow :execresult
 DELETE FROM feed_follows
 WHERE feed_follows.user_id = $1
-AND feed_follows.feed_id = (
-    SELECT id FROM feeds WHERE url = $2
+AND feed_follows.feed_id IN (
+    SELECT id FROM feeds WHERE url = $2 OR url_key = $3
+    UNION
+    SELECT feed_id FROM feed_aliases WHERE feed_aliases.url = $2 OR feed_aliases.url_key = $3
 )
 `
 
 type DeleteFeedFollowParams struct {
 	UserID uuid.UUID
 	Url    string
+	UrlKey string
 }
 
 func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowParams) (sql.Result, error) {
-	return q.db.ExecContext(ctx, deleteFeedFollow, arg.UserID, arg.Url)
+	return q.db.ExecContext(ctx, deleteFeedFollow, arg.UserID, arg.Url, arg.UrlKey)
 }
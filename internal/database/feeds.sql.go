@@ -7,14 +7,31 @@ package database
 
 import (
 	"context"
+
+	"github.com/lib/pq"
 )
 
 const getFeedByUrl = `-- name: GetFeedByUrl :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE url = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, consecutive_failures, last_success_at, last_status, last_error, response_time_ms, item_count, enabled, disabled_reason, site_url, description, language, image_url, url_key, ttl_minutes, skip_hours, skip_days FROM feeds
+WHERE url = $1
+OR url_key = $2
+OR id = (
+    SELECT feed_id FROM feed_aliases
+    WHERE feed_aliases.url = $1 OR feed_aliases.url_key = $2
+    ORDER BY feed_aliases.url = $1 DESC
+    LIMIT 1
+)
+ORDER BY url = $1 DESC, url_key = $2 DESC
+LIMIT 1
 `
 
-func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getFeedByUrl, url)
+type GetFeedByUrlParams struct {
+	Url    string
+	UrlKey string
+}
+
+func (q *Queries) GetFeedByUrl(ctx context.Context, arg GetFeedByUrlParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByUrl, arg.Url, arg.UrlKey)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -24,6 +41,25 @@ func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.LastStatus,
+		&i.LastError,
+		&i.ResponseTimeMs,
+		&i.ItemCount,
+		&i.Enabled,
+		&i.DisabledReason,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.UrlKey,
+		&i.TtlMinutes,
+		pq.Array(&i.SkipHours),
+		pq.Array(&i.SkipDays),
 	)
 	return i, err
 }
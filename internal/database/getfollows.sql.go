@@ -10,27 +10,39 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
 SELECT
-    feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id,
+    feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.tags,
     feeds.name AS feed_name,
-    users.name AS user_name
+    users.name AS user_name,
+    (
+        SELECT COUNT(*)
+        FROM posts
+        LEFT JOIN post_states ON post_states.post_id = posts.id
+            AND post_states.user_id = feed_follows.user_id
+        WHERE posts.feed_id = feed_follows.feed_id
+        AND NOT COALESCE(post_states.read, FALSE)
+    ) AS unread_count
 FROM feed_follows
 INNER JOIN feeds on feed_follows.feed_id = feeds.id
 INNER JOIN users on feed_follows.user_id = users.id
 WHERE feed_follows.user_id = $1
+ORDER BY feeds.name
 `
 
 type GetFeedFollowsForUserRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	FeedName  string
-	UserName  string
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	FeedID      uuid.UUID
+	Tags        []string
+	FeedName    string
+	UserName    string
+	UnreadCount int64
 }
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
@@ -48,8 +60,10 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			pq.Array(&i.Tags),
 			&i.FeedName,
 			&i.UserName,
+			&i.UnreadCount,
 		); err != nil {
 			return nil, err
 		}
@@ -7,33 +7,109 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id 
+const browsePosts = `-- name: BrowsePosts :many
+SELECT
+    posts.id,
+    posts.created_at,
+    posts.updated_at,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.feed_id,
+    posts.content,
+    posts.author,
+    posts.categories,
+    posts.edited_at,
+    feeds.name AS feed_name,
+    COALESCE(post_states.read, FALSE) AS read,
+    (CASE WHEN $1::bool THEN posts.created_at
+        ELSE COALESCE(posts.published_at, posts.created_at) END)::timestamp AS sort_time
 FROM posts
 JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
-WHERE feed_follows.user_id = $1
-ORDER BY posts.published_at DESC
-LIMIT $2
+JOIN feeds ON posts.feed_id = feeds.id
+LEFT JOIN post_states ON post_states.post_id = posts.id
+    AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $2
+AND (NOT $3::bool OR NOT COALESCE(post_states.read, FALSE))
+AND ($4::uuid IS NULL OR posts.feed_id = $4)
+AND ($5::timestamp IS NULL OR (CASE WHEN $1::bool THEN posts.created_at
+    ELSE COALESCE(posts.published_at, posts.created_at) END) >= $5)
+AND ($6::timestamp IS NULL OR (CASE WHEN $1::bool THEN posts.created_at
+    ELSE COALESCE(posts.published_at, posts.created_at) END) < $6)
+AND ($7::text IS NULL OR posts.author ILIKE '%' || $7 || '%')
+AND ($8::text IS NULL OR EXISTS (
+    SELECT 1 FROM unnest(posts.categories) AS category WHERE lower(category) = lower($8)
+))
+AND ($9::text IS NULL OR lower($9) = ANY(feed_follows.tags))
+AND ($10::timestamp IS NULL OR ((CASE WHEN $1::bool THEN posts.created_at
+    ELSE COALESCE(posts.published_at, posts.created_at) END), posts.id) < ($10, $11::uuid))
+ORDER BY sort_time DESC, posts.id DESC
+LIMIT $12
 `
 
-type GetPostsForUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
+type BrowsePostsParams struct {
+	SortByFetched bool
+	UserID        uuid.UUID
+	UnreadOnly    bool
+	FeedID        uuid.NullUUID
+	Since         sql.NullTime
+	Until         sql.NullTime
+	Author        sql.NullString
+	Category      sql.NullString
+	Tag           sql.NullString
+	AfterTime     sql.NullTime
+	AfterID       uuid.NullUUID
+	Limit         int32
 }
 
-func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+type BrowsePostsRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	FeedID      uuid.UUID
+	Content     sql.NullString
+	Author      sql.NullString
+	Categories  []string
+	EditedAt    sql.NullTime
+	FeedName    string
+	Read        bool
+	SortTime    time.Time
+}
+
+func (q *Queries) BrowsePosts(ctx context.Context, arg BrowsePostsParams) ([]BrowsePostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, browsePosts,
+		arg.SortByFetched,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.FeedID,
+		arg.Since,
+		arg.Until,
+		arg.Author,
+		arg.Category,
+		arg.Tag,
+		arg.AfterTime,
+		arg.AfterID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Post
+	var items []BrowsePostsRow
 	for rows.Next() {
-		var i Post
+		var i BrowsePostsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
@@ -43,6 +119,13 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Content,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.EditedAt,
+			&i.FeedName,
+			&i.Read,
+			&i.SortTime,
 		); err != nil {
 			return nil, err
 		}
@@ -11,23 +11,43 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, users.name AS user_name
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.etag, feeds.last_modified, feeds.next_fetch_at, feeds.consecutive_failures, feeds.last_success_at, feeds.last_status, feeds.last_error, feeds.response_time_ms, feeds.item_count, feeds.enabled, feeds.disabled_reason, feeds.site_url, feeds.description, feeds.language, feeds.image_url, feeds.url_key, feeds.ttl_minutes, feeds.skip_hours, feeds.skip_days, users.name AS user_name
 FROM feeds
 JOIN users ON feeds.user_id = users.id
 `
 
 type GetFeedsRow struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
-	UserName      string
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	UserID              uuid.UUID
+	LastFetchedAt       sql.NullTime
+	Etag                sql.NullString
+	LastModified        sql.NullString
+	NextFetchAt         sql.NullTime
+	ConsecutiveFailures int32
+	LastSuccessAt       sql.NullTime
+	LastStatus          sql.NullInt32
+	LastError           sql.NullString
+	ResponseTimeMs      sql.NullInt32
+	ItemCount           sql.NullInt32
+	Enabled             bool
+	DisabledReason      sql.NullString
+	SiteUrl             sql.NullString
+	Description         sql.NullString
+	Language            sql.NullString
+	ImageUrl            sql.NullString
+	UrlKey              string
+	TtlMinutes          sql.NullInt32
+	SkipHours           []int32
+	SkipDays            []int32
+	UserName            string
 }
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
@@ -47,6 +67,25 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.NextFetchAt,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.LastStatus,
+			&i.LastError,
+			&i.ResponseTimeMs,
+			&i.ItemCount,
+			&i.Enabled,
+			&i.DisabledReason,
+			&i.SiteUrl,
+			&i.Description,
+			&i.Language,
+			&i.ImageUrl,
+			&i.UrlKey,
+			&i.TtlMinutes,
+			pq.Array(&i.SkipHours),
+			pq.Array(&i.SkipDays),
 			&i.UserName,
 		); err != nil {
 			return nil, err
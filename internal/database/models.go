@@ -12,13 +12,39 @@ import (
 )
 
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	UserID              uuid.UUID
+	LastFetchedAt       sql.NullTime
+	Etag                sql.NullString
+	LastModified        sql.NullString
+	NextFetchAt         sql.NullTime
+	ConsecutiveFailures int32
+	LastSuccessAt       sql.NullTime
+	LastStatus          sql.NullInt32
+	LastError           sql.NullString
+	ResponseTimeMs      sql.NullInt32
+	ItemCount           sql.NullInt32
+	Enabled             bool
+	DisabledReason      sql.NullString
+	SiteUrl             sql.NullString
+	Description         sql.NullString
+	Language            sql.NullString
+	ImageUrl            sql.NullString
+	UrlKey              string
+	TtlMinutes          sql.NullInt32
+	SkipHours           []int32
+	SkipDays            []int32
+}
+
+type FeedAlias struct {
+	Url       string
+	FeedID    uuid.UUID
+	CreatedAt time.Time
+	UrlKey    string
 }
 
 type FeedFollow struct {
@@ -27,17 +53,63 @@ type FeedFollow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
+	Tags      []string
 }
 
 type Post struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          sql.NullTime
+	FeedID               uuid.UUID
+	SearchVector         interface{}
+	Content              sql.NullString
+	Author               sql.NullString
+	Categories           []string
+	Guid                 sql.NullString
+	CommentsUrl          sql.NullString
+	EnclosureUrl         sql.NullString
+	EnclosureType        sql.NullString
+	EnclosureLength      sql.NullInt64
+	DedupeKey            string
+	EditedAt             sql.NullTime
+	PublishedAtEstimated bool
+	OriginalUrl          sql.NullString
+	UrlResolved          bool
+}
+
+type PostRevision struct {
 	ID          uuid.UUID
+	PostID      uuid.UUID
+	CreatedAt   time.Time
+	Title       string
+	Description sql.NullString
+	Content     sql.NullString
+}
+
+type PostState struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Read      bool
+	ReadAt    sql.NullTime
+}
+
+type SavedPost struct {
+	UserID      uuid.UUID
+	PostID      uuid.UUID
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	Title       string
 	Url         string
 	Description sql.NullString
 	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	FeedName    string
+	Note        sql.NullString
 }
 
 type User struct {
@@ -0,0 +1,356 @@
+// Package htmltext renders the HTML found in feed items as plain text
+// that reads well in a terminal.
+package htmltext
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Render converts an HTML fragment into plain text. Paragraphs and other
+// blocks are separated by blank lines, list items get bullets or numbers,
+// links are numbered and listed as footnotes at the end, and scripts,
+// styles and other invisible elements are dropped. The result is
+// Printable, so it can go to a terminal as is.
+func Render(src string) string {
+	if strings.TrimSpace(src) == "" {
+		return ""
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(src), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		// The parser only fails on reader errors, fall back to the raw text
+		return Printable(strings.TrimSpace(src))
+	}
+
+	r := &renderer{atLineStart: true}
+	for _, n := range nodes {
+		r.node(n)
+	}
+	return Printable(r.String())
+}
+
+// Printable drops the control characters in s other than tabs and line
+// breaks, so text from feeds can't move the cursor or change the state of
+// the terminal with escape sequences. Titles and other plain text from
+// feeds need it before they are printed.
+func Printable(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t', r == '\n':
+			return r
+		case r < 0x20, r >= 0x7f && r <= 0x9f:
+			return -1
+		}
+		return r
+	}, s)
+}
+
+type renderer struct {
+	sb strings.Builder
+	// prefix is written at the start of every line, it holds the list
+	// indentation and blockquote markers of the enclosing elements
+	prefix []string
+	// newlines is the number of line breaks owed before the next text
+	newlines    int
+	space       bool
+	atLineStart bool
+	pre         int
+	lists       int
+	// itemStart is set between a list marker and the item's first text,
+	// block breaks there would leave the marker alone on its line
+	itemStart bool
+	links     []string
+}
+
+// skipped elements have no readable content
+var skipped = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Head:     true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Svg:      true,
+	atom.Form:     true,
+	atom.Button:   true,
+}
+
+// blocks are separated from their surroundings by a blank line
+var blocks = map[atom.Atom]bool{
+	atom.P:          true,
+	atom.Div:        true,
+	atom.Section:    true,
+	atom.Article:    true,
+	atom.Header:     true,
+	atom.Footer:     true,
+	atom.Aside:      true,
+	atom.Nav:        true,
+	atom.Main:       true,
+	atom.H1:         true,
+	atom.H2:         true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.H5:         true,
+	atom.H6:         true,
+	atom.Pre:        true,
+	atom.Table:      true,
+	atom.Figure:     true,
+	atom.Figcaption: true,
+	atom.Dl:         true,
+	atom.Address:    true,
+}
+
+func (r *renderer) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		r.text(n.Data)
+		return
+	case html.ElementNode:
+	case html.DocumentNode:
+		r.children(n)
+		return
+	default:
+		return
+	}
+
+	if skipped[n.DataAtom] {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		r.addBreak()
+	case atom.Hr:
+		r.lineBreak(2)
+		r.write("----")
+		r.lineBreak(2)
+	case atom.Img:
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			r.text(" [image: " + alt + "] ")
+		}
+	case atom.A:
+		r.children(n)
+		r.link(n)
+	case atom.Ul, atom.Ol:
+		// Nested lists follow their item without a blank line
+		gap := 2
+		if r.lists > 0 {
+			gap = 1
+		}
+		r.lineBreak(gap)
+		r.lists++
+		r.list(n)
+		r.lists--
+		r.lineBreak(gap)
+	case atom.Blockquote:
+		r.lineBreak(2)
+		r.prefix = append(r.prefix, "> ")
+		r.children(n)
+		r.prefix = r.prefix[:len(r.prefix)-1]
+		r.lineBreak(2)
+	case atom.Pre:
+		r.lineBreak(2)
+		r.pre++
+		r.children(n)
+		r.pre--
+		r.lineBreak(2)
+	case atom.Tr, atom.Dt:
+		r.lineBreak(1)
+		r.children(n)
+		r.lineBreak(1)
+	case atom.Dd:
+		r.lineBreak(1)
+		r.prefix = append(r.prefix, "    ")
+		r.children(n)
+		r.prefix = r.prefix[:len(r.prefix)-1]
+		r.lineBreak(1)
+	case atom.Td, atom.Th:
+		r.children(n)
+		r.text("  ")
+	default:
+		if blocks[n.DataAtom] {
+			r.lineBreak(2)
+			r.children(n)
+			r.lineBreak(2)
+			return
+		}
+		r.children(n)
+	}
+}
+
+func (r *renderer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.node(c)
+	}
+}
+
+// list renders the items of a <ul> or <ol>, indenting their content so
+// wrapped lines and nested lists line up under the item text.
+func (r *renderer) list(n *html.Node) {
+	number := 1
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+			r.node(c)
+			continue
+		}
+
+		marker := "• "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", number)
+			number++
+		}
+
+		r.lineBreak(1)
+		r.write(marker)
+		r.space = false
+		r.itemStart = true
+		r.prefix = append(r.prefix, strings.Repeat(" ", len([]rune(marker))))
+		r.children(c)
+		r.prefix = r.prefix[:len(r.prefix)-1]
+		r.itemStart = false
+		r.lineBreak(1)
+	}
+}
+
+// link adds a footnote marker after a link's text, unless the text already
+// is the URL or the link leads nowhere useful.
+func (r *renderer) link(n *html.Node) {
+	href := strings.TrimSpace(attr(n, "href"))
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return
+	}
+	if strings.TrimSpace(textContent(n)) == href {
+		return
+	}
+	r.links = append(r.links, href)
+	r.write(fmt.Sprintf("[%d]", len(r.links)))
+}
+
+var whitespace = regexp.MustCompile(`[ \t\r\n\f]+`)
+
+func (r *renderer) text(s string) {
+	if r.pre > 0 {
+		r.write(s)
+		return
+	}
+
+	s = whitespace.ReplaceAllString(s, " ")
+	if s == "" {
+		return
+	}
+	if s == " " {
+		r.space = true
+		return
+	}
+	if strings.HasPrefix(s, " ") {
+		r.space = true
+	}
+	trailing := strings.HasSuffix(s, " ")
+	s = strings.TrimSpace(s)
+
+	if r.space && !r.atLineStart && r.newlines == 0 {
+		r.sb.WriteString(" ")
+	}
+	r.write(s)
+	r.space = trailing
+}
+
+// write outputs s as is, settling owed line breaks first and starting
+// every new line with the current prefix.
+func (r *renderer) write(s string) {
+	if r.newlines > 0 {
+		if r.sb.Len() > 0 {
+			r.sb.WriteString("\n")
+			// Blank lines keep the markers of the enclosing quotes
+			blank := strings.TrimRight(strings.Join(r.prefix, ""), " ")
+			for range r.newlines - 1 {
+				r.sb.WriteString(blank + "\n")
+			}
+			r.atLineStart = true
+		}
+		r.newlines = 0
+		r.space = false
+	}
+
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			r.sb.WriteString("\n")
+			r.atLineStart = true
+		}
+		if line == "" {
+			continue
+		}
+		if r.atLineStart {
+			r.sb.WriteString(strings.Join(r.prefix, ""))
+			r.atLineStart = false
+		}
+		r.sb.WriteString(line)
+		r.itemStart = false
+	}
+}
+
+// lineBreak asks for n line breaks before the next text. Requests don't
+// add up, so nested blocks still produce a single blank line.
+func (r *renderer) lineBreak(n int) {
+	if r.itemStart {
+		return
+	}
+	r.newlines = max(r.newlines, n)
+}
+
+// addBreak asks for one more line break than is already owed, for <br>.
+// Two in a row make a blank line, like the paragraph break they stand in
+// for, and more than that still make just one.
+func (r *renderer) addBreak() {
+	if r.itemStart {
+		return
+	}
+	r.newlines = min(r.newlines+1, 2)
+}
+
+func (r *renderer) String() string {
+	// Only line breaks are trimmed at the start, preformatted text keeps
+	// the indentation of its first line
+	text := strings.TrimRight(strings.TrimLeft(r.sb.String(), "\n"), " \n")
+	if len(r.links) == 0 {
+		return text
+	}
+
+	var sb strings.Builder
+	sb.WriteString(text)
+	sb.WriteString("\n\n")
+	for i, link := range r.links {
+		fmt.Fprintf(&sb, "[%d] %s\n", i+1, link)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(textContent(c))
+	}
+	return sb.String()
+}
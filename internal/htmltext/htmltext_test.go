@@ -0,0 +1,55 @@
+package htmltext
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty", "", ""},
+		{"whitespace only", "  \n ", ""},
+		{"plain text", "plain text", "plain text"},
+		{"inline elements", "Hello <b>bold</b> world", "Hello bold world"},
+		{"entities", "a&amp;b &lt;tag&gt; &eacute;", "a&b <tag> é"},
+		{"collapsed whitespace", "<p>  lots   of\n\n  space </p>", "lots of space"},
+		{"paragraphs", "<p>One</p><p>Two</p>", "One\n\nTwo"},
+		{"nested blocks", "<div><p>nested</p></div><p>blocks</p>", "nested\n\nblocks"},
+		{"line break", "line<br>break", "line\nbreak"},
+		{"double line break", "x<br><br>y", "x\n\ny"},
+		{"many line breaks", "x<br><br><br><br>y", "x\n\ny"},
+		{"line break before paragraph", "<p>x<br></p><p>y</p>", "x\n\ny"},
+		{"horizontal rule", "a<hr>b", "a\n\n----\n\nb"},
+		{"skipped elements", "<p>x</p><script>alert(1)</script><style>p{}</style><p>y</p>", "x\n\ny"},
+		{"unordered list", "<ul><li>one</li><li>two</li></ul>", "• one\n• two"},
+		{"ordered list", "<ol><li>first</li><li>second</li></ol>", "1. first\n2. second"},
+		{"paragraphs in list items", "<ul><li><p>one</p></li><li><p>two</p><p>more</p></li></ul>", "• one\n\n• two\n\n  more"},
+		{"line break at item start", "<ol><li><br>first</li></ol>", "1. first"},
+		{"empty list item", "<ul><li></li><li>next</li></ul>", "• \n• next"},
+		{"nested list", "<ul><li>outer<ul><li>inner</li></ul></li><li>next</li></ul>", "• outer\n  • inner\n• next"},
+		{"list between paragraphs", "<p>a</p><ul><li>b</li></ul><p>c</p>", "a\n\n• b\n\nc"},
+		{"blockquote", "<blockquote><p>one</p><p>two</p></blockquote>", "> one\n>\n> two"},
+		{"preformatted", "<pre>  code\n    indented</pre>", "  code\n    indented"},
+		{"image alt", `<img src="x.png" alt="A cat">`, "[image: A cat]"},
+		{"image without alt", `before<img src="x.png">after`, "beforeafter"},
+		{"table", "<table><tr><td>a</td><td>b</td></tr><tr><td>c</td><td>d</td></tr></table>", "a b\nc d"},
+		{"definition list", "<dl><dt>term</dt><dd>definition</dd></dl>", "term\n    definition"},
+		{
+			"links become footnotes",
+			`Read <a href="https://example.com/a">the post</a> and <a href="https://example.com/b">more</a>.`,
+			"Read the post[1] and more[2].\n\n[1] https://example.com/a\n[2] https://example.com/b",
+		},
+		{"link text is the URL", `<a href="https://example.com/">https://example.com/</a>`, "https://example.com/"},
+		{"fragment and script links", `<a href="#top">top</a> <a href="javascript:void(0)">js</a>`, "top js"},
+		{"escape sequences", "<p>red \x1b[31mtext\x1b[0m</p>", "red [31mtext[0m"},
+		{"escaped control characters", "a&#27;]0;title&#7;b", "a]0;titleb"},
+		{"c1 controls", "a\u009b31mb", "a31mb"},
+		{"preformatted controls", "<pre>a\x1b[2J\tb\r\nc</pre>", "a[2J\tb\nc"},
+	}
+	for _, tt := range tests {
+		if got := Render(tt.input); got != tt.want {
+			t.Errorf("%s: Render(%q) = %q, want %q", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
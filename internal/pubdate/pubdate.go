@@ -0,0 +1,197 @@
+// Package pubdate parses the publication dates found in feeds. The specs
+// ask for RFC 822 or RFC 3339, but real feeds use almost anything that
+// looks like a date, so Parse normalizes the common deviations before
+// trying a broad set of layouts.
+package pubdate
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrEmpty is returned for a blank date.
+var ErrEmpty = errors.New("empty date")
+
+// layouts are tried in order after normalization. Weekdays are stripped
+// and zone names are replaced with numeric offsets beforehand, and a
+// missing zone means UTC.
+var layouts = []string{
+	// RFC 822, 1123 and relatives, days and hours may be a single digit
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 -0700",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 January 2006 15:04:05",
+	"2 January 2006 15:04",
+	"2 Jan 2006 3:04:05 PM -0700",
+	"2 Jan 2006 3:04 PM -0700",
+	"2 Jan 2006 3:04 PM",
+	"2 Jan 2006",
+	"2 January 2006",
+	"2-Jan-2006 15:04:05 -0700",
+	"2-Jan-06 15:04:05 -0700",
+
+	// ISO 8601 and RFC 3339, fractional seconds are optional
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04Z0700",
+	"2006-01-02 15:04 -0700",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"20060102T150405Z0700",
+	"2006-01-02",
+	"2006/01/02 15:04:05 -0700",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+
+	// US style month first, as written by hand or by date(1)
+	"January 2, 2006 15:04:05 -0700",
+	"January 2, 2006 3:04 PM -0700",
+	"January 2, 2006 3:04 PM",
+	"January 2, 2006 15:04",
+	"January 2, 2006",
+	"Jan 2, 2006 15:04:05 -0700",
+	"Jan 2, 2006 3:04 PM -0700",
+	"Jan 2, 2006 3:04 PM",
+	"Jan 2, 2006 15:04",
+	"Jan 2, 2006",
+	"Jan 2 15:04:05 -0700 2006",
+	"Jan 2 15:04:05 2006",
+	"Jan 2 2006 15:04:05 -0700",
+	"Jan 2 2006",
+}
+
+// zones maps the zone abbreviations seen in feeds to their offsets.
+// Abbreviations are ambiguous, the most common meaning in feeds wins.
+var zones = map[string]string{
+	"UT":   "+0000",
+	"UTC":  "+0000",
+	"GMT":  "+0000",
+	"Z":    "+0000",
+	"WET":  "+0000",
+	"WEST": "+0100",
+	"BST":  "+0100",
+	"IST":  "+0530",
+	"CET":  "+0100",
+	"CEST": "+0200",
+	"MET":  "+0100",
+	"MEST": "+0200",
+	"EET":  "+0200",
+	"EEST": "+0300",
+	"MSK":  "+0300",
+	"SGT":  "+0800",
+	"HKT":  "+0800",
+	"AWST": "+0800",
+	"JST":  "+0900",
+	"KST":  "+0900",
+	"ACST": "+0930",
+	"ACDT": "+1030",
+	"AEST": "+1000",
+	"AEDT": "+1100",
+	"NZST": "+1200",
+	"NZDT": "+1300",
+	"NST":  "-0330",
+	"NDT":  "-0230",
+	"AST":  "-0400",
+	"ADT":  "-0300",
+	"EST":  "-0500",
+	"EDT":  "-0400",
+	"CST":  "-0600",
+	"CDT":  "-0500",
+	"MST":  "-0700",
+	"MDT":  "-0600",
+	"PST":  "-0800",
+	"PDT":  "-0700",
+	"AKST": "-0900",
+	"AKDT": "-0800",
+	"HST":  "-1000",
+}
+
+var (
+	comment     = regexp.MustCompile(`\s*\([^)]*\)\s*$`)
+	weekday     = regexp.MustCompile(`^(?i:(mon|tue|wed|thu|fri|sat|sun)[a-z]*\.?,?\s+)`)
+	zoneName    = regexp.MustCompile(`\s([A-Za-z]{1,5})$`)
+	gmtOffset   = regexp.MustCompile(`\s(?i:GMT|UTC)\s?([+-]\d{1,2})(?::?(\d{2}))?$`)
+	colonOffset = regexp.MustCompile(`([+-]\d{2}):(\d{2})$`)
+	shortOffset = regexp.MustCompile(`(\s|T[\d:.]+)([+-]\d{2})$`)
+	ordinal     = regexp.MustCompile(`(\d)(?i:st|nd|rd|th)\b`)
+	sept        = regexp.MustCompile(`(?i)\bSept\b`)
+	at          = regexp.MustCompile(`(?i)\s(at|@)\s`)
+	meridiem    = regexp.MustCompile(`(?i)(\d)\s*([ap])\.?m\b\.?`)
+)
+
+// Parse returns the time a date string from a feed stands for, in UTC.
+func Parse(value string) (time.Time, error) {
+	s := normalize(value)
+	if s == "" {
+		return time.Time{}, ErrEmpty
+	}
+
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		// Zero dates are placeholders, not publication dates
+		if t.Year() < 1900 {
+			return time.Time{}, fmt.Errorf("implausible date %q", value)
+		}
+		return t.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", value)
+}
+
+// normalize rewrites a date into a shape the layouts cover.
+func normalize(value string) string {
+	s := strings.Join(strings.Fields(value), " ")
+	s = comment.ReplaceAllString(s, "")
+	s = weekday.ReplaceAllString(s, "")
+	s = ordinal.ReplaceAllString(s, "$1")
+	s = sept.ReplaceAllString(s, "Sep")
+	s = at.ReplaceAllString(s, " ")
+	// "3:04pm" and "3:04 p.m." become "3:04 PM"
+	s = meridiem.ReplaceAllStringFunc(s, func(m string) string {
+		sub := meridiem.FindStringSubmatch(m)
+		return sub[1] + " " + strings.ToUpper(sub[2]) + "M"
+	})
+
+	// "GMT+2" and "UTC-05:00"
+	if m := gmtOffset.FindStringSubmatch(s); m != nil {
+		hours := m[1]
+		if len(hours) == 2 {
+			hours = hours[:1] + "0" + hours[1:]
+		}
+		minutes := m[2]
+		if minutes == "" {
+			minutes = "00"
+		}
+		s = s[:len(s)-len(m[0])] + " " + hours + minutes
+	}
+
+	// "EDT", "GMT" and friends, ISO dates end in a bare Z which parses as
+	// is. An unknown zone is dropped, a date a few hours off still sorts
+	// better than no date at all.
+	if m := zoneName.FindStringSubmatch(s); m != nil {
+		name := strings.ToUpper(m[1])
+		if offset, ok := zones[name]; ok {
+			s = s[:len(s)-len(m[0])] + " " + offset
+		} else if name != "AM" && name != "PM" {
+			s = s[:len(s)-len(m[0])]
+		}
+	}
+
+	s = colonOffset.ReplaceAllString(s, "$1$2")
+	s = shortOffset.ReplaceAllString(s, "$1${2}00")
+	return s
+}
@@ -0,0 +1,97 @@
+package pubdate
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCorpus(t *testing.T) {
+	file, err := os.Open("testdata/corpus.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	samples := 0
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		input, want, ok := strings.Cut(text, "\t")
+		if !ok {
+			t.Fatalf("corpus.txt:%d: missing tab between date and expected value", line)
+		}
+		samples++
+
+		got, err := Parse(input)
+		if want == "error" {
+			if err == nil {
+				t.Errorf("corpus.txt:%d: Parse(%q) = %v, want an error", line, input, got)
+			}
+			continue
+		}
+
+		wantTime, err2 := time.Parse(time.RFC3339Nano, want)
+		if err2 != nil {
+			t.Fatalf("corpus.txt:%d: bad expected value %q: %v", line, want, err2)
+		}
+		if err != nil {
+			t.Errorf("corpus.txt:%d: Parse(%q) failed: %v", line, input, err)
+			continue
+		}
+		if !got.Equal(wantTime) {
+			t.Errorf("corpus.txt:%d: Parse(%q) = %v, want %v", line, input, got, wantTime)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if samples == 0 {
+		t.Fatal("corpus.txt has no samples")
+	}
+}
+
+func TestParseReturnsUTC(t *testing.T) {
+	got, err := Parse("Wed, 02 Oct 2002 13:00:00 EDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Location() != time.UTC {
+		t.Errorf("location = %v, want UTC", got.Location())
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	for _, input := range []string{"", "   ", "\n\t"} {
+		_, err := Parse(input)
+		if !errors.Is(err, ErrEmpty) {
+			t.Errorf("Parse(%q) error = %v, want ErrEmpty", input, err)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Mon, 02 Jan 2006 15:04:05 EDT", "02 Jan 2006 15:04:05 -0400"},
+		{"Tue, 10 Jun 2003 04:00:00 +0000 (UTC)", "10 Jun 2003 04:00:00 +0000"},
+		{"Mon, 02 Jan 2006 15:04:05 GMT+5:30", "02 Jan 2006 15:04:05 +0530"},
+		{"2006-01-02T15:04:05+07:00", "2006-01-02T15:04:05+0700"},
+		{"2006-01-02T15:04:05Z", "2006-01-02T15:04:05Z"},
+		{"2006-01-02", "2006-01-02"},
+		{"March 3rd, 2024 at 9:05 a.m.", "March 3, 2024 9:05 AM"},
+	}
+	for _, tt := range tests {
+		if got := normalize(tt.input); got != tt.want {
+			t.Errorf("normalize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
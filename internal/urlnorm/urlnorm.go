@@ -0,0 +1,159 @@
+// Package urlnorm canonicalizes the URLs of feeds and posts, so the same
+// address written in different ways is stored and compared as one.
+package urlnorm
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// defaultPorts are dropped from hosts, "example.com:443" is "example.com"
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Normalize returns the canonical form of an http or https URL: scheme and
+// host are lowercased, the default port and utm_* tracking parameters are
+// dropped and an empty path becomes "/". It only makes changes that keep
+// the URL pointing at the same resource, so the result is safe to fetch.
+func Normalize(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("not an http or https URL: %q", raw)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("missing host in URL %q", raw)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	port := u.Port()
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		// IPv6 addresses keep their brackets
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.RawQuery = stripParams(u.RawQuery, isUTM)
+	if u.RawQuery == "" {
+		u.ForceQuery = false
+	}
+	return u.String(), nil
+}
+
+// StripTracking removes all known tracking parameters from a URL, not just
+// the utm_* ones Normalize drops. These identify the campaign or the click
+// that led to a page, which makes a link personal. A URL that can't be
+// parsed is returned as is.
+func StripTracking(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return raw
+	}
+	u.RawQuery = stripParams(u.RawQuery, isTracking)
+	if u.RawQuery == "" {
+		u.ForceQuery = false
+	}
+	return u.String()
+}
+
+// Key returns the identity of a URL, two URLs with the same key are taken
+// to be the same resource. On top of Normalize it ignores the scheme, so
+// the http and https versions of a feed match, and a trailing slash. A
+// URL that can't be normalized is its own key.
+func Key(raw string) string {
+	normalized, err := Normalize(raw)
+	if err != nil {
+		return strings.TrimSpace(raw)
+	}
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return normalized
+	}
+
+	key := u.Host + strings.TrimSuffix(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	if u.Fragment != "" {
+		key += "#" + u.EscapedFragment()
+	}
+	return key
+}
+
+// trackingParams are added by ad networks, analytics and newsletter tools
+var trackingParams = map[string]bool{
+	"fbclid":      true,
+	"gclid":       true,
+	"dclid":       true,
+	"gbraid":      true,
+	"wbraid":      true,
+	"msclkid":     true,
+	"yclid":       true,
+	"twclid":      true,
+	"igshid":      true,
+	"mc_cid":      true,
+	"mc_eid":      true,
+	"_hsenc":      true,
+	"_hsmi":       true,
+	"__hstc":      true,
+	"__hssc":      true,
+	"__hsfp":      true,
+	"mkt_tok":     true,
+	"oly_anon_id": true,
+	"oly_enc_id":  true,
+	"vero_id":     true,
+	"vero_conv":   true,
+	"_openstat":   true,
+	"ref_src":     true,
+	"ref_url":     true,
+}
+
+// stripParams removes the parameters matched by drop from a raw query
+// string. The other parameters keep their order and encoding.
+func stripParams(rawQuery string, drop func(name string) bool) string {
+	if rawQuery == "" {
+		return ""
+	}
+	var kept []string
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if drop(name) {
+			continue
+		}
+		kept = append(kept, pair)
+	}
+	return strings.Join(kept, "&")
+}
+
+func isUTM(name string) bool {
+	return strings.HasPrefix(strings.ToLower(name), "utm_")
+}
+
+// isTracking reports whether a query parameter only exists to track where
+// a visitor came from.
+func isTracking(name string) bool {
+	return isUTM(name) || trackingParams[strings.ToLower(name)]
+}
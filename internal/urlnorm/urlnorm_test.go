@@ -0,0 +1,124 @@
+package urlnorm
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"already canonical", "https://example.com/feed.xml", "https://example.com/feed.xml"},
+		{"surrounding space", "  https://example.com/feed  ", "https://example.com/feed"},
+		{"uppercase scheme and host", "HTTPS://Example.COM/Feed", "https://example.com/Feed"},
+		{"empty path", "https://example.com", "https://example.com/"},
+		{"default http port", "http://example.com:80/feed", "http://example.com/feed"},
+		{"default https port", "https://example.com:443/feed", "https://example.com/feed"},
+		{"other port", "https://example.com:8443/feed", "https://example.com:8443/feed"},
+		{"port of the other scheme", "http://example.com:443/feed", "http://example.com:443/feed"},
+		{"trailing dot in host", "https://example.com./feed", "https://example.com/feed"},
+		{"ipv6 host", "http://[::1]:80/feed", "http://[::1]/feed"},
+		{"ipv6 host with port", "http://[::1]:8080/feed", "http://[::1]:8080/feed"},
+		{"utm parameters", "https://example.com/a?utm_source=x&id=1&UTM_Medium=y", "https://example.com/a?id=1"},
+		{"only utm parameters", "https://example.com/a?utm_source=x", "https://example.com/a"},
+		{"empty query", "https://example.com/a?", "https://example.com/a"},
+		{"other tracking kept", "https://example.com/a?fbclid=1", "https://example.com/a?fbclid=1"},
+		{"query order and encoding kept", "https://example.com/a?b=2&a=%2F", "https://example.com/a?b=2&a=%2F"},
+		{"fragment kept", "https://example.com/a#part", "https://example.com/a#part"},
+		{"trailing slash kept", "https://example.com/a/", "https://example.com/a/"},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.input)
+		if err != nil {
+			t.Errorf("%s: Normalize(%q) returned error: %v", tt.name, tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: Normalize(%q) = %q, want %q", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"relative", "/posts/1"},
+		{"other scheme", "ftp://example.com/feed"},
+		{"mailto", "mailto:someone@example.com"},
+		{"missing host", "https:///feed"},
+		{"invalid", "https://exa mple.com/%zz"},
+	}
+	for _, tt := range tests {
+		if got, err := Normalize(tt.input); err == nil {
+			t.Errorf("%s: Normalize(%q) = %q, want an error", tt.name, tt.input, got)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"https", "https://example.com/feed", "example.com/feed"},
+		{"http", "http://example.com/feed", "example.com/feed"},
+		{"trailing slash", "https://example.com/feed/", "example.com/feed"},
+		{"root", "https://example.com/", "example.com"},
+		{"no path", "https://example.com", "example.com"},
+		{"case and port", "HTTP://EXAMPLE.com:80/feed", "example.com/feed"},
+		{"port kept", "https://example.com:8443/feed", "example.com:8443/feed"},
+		{"query", "https://example.com/feed?format=rss&utm_source=x", "example.com/feed?format=rss"},
+		{"fragment", "https://example.com/feed#top", "example.com/feed#top"},
+		{"escaped path", "https://example.com/a%20b/", "example.com/a%20b"},
+		{"not normalizable", "  /relative/feed ", "/relative/feed"},
+	}
+	for _, tt := range tests {
+		if got := Key(tt.input); got != tt.want {
+			t.Errorf("%s: Key(%q) = %q, want %q", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestKeyMatchesSpellings(t *testing.T) {
+	spellings := []string{
+		"https://example.com/feed",
+		"http://example.com/feed/",
+		"HTTPS://Example.com:443/feed?utm_campaign=x",
+		"https://example.com./feed",
+	}
+	want := Key(spellings[0])
+	for _, s := range spellings[1:] {
+		if got := Key(s); got != want {
+			t.Errorf("Key(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestStripTracking(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"no query", "https://example.com/a", "https://example.com/a"},
+		{"utm", "https://example.com/a?utm_source=x&utm_medium=y", "https://example.com/a"},
+		{"click ids", "https://example.com/a?fbclid=1&gclid=2&msclkid=3", "https://example.com/a"},
+		{"newsletter ids", "https://example.com/a?mc_cid=1&mc_eid=2&_hsenc=3", "https://example.com/a"},
+		{"case insensitive", "https://example.com/a?FBCLID=1&Utm_Source=x", "https://example.com/a"},
+		{"escaped name", "https://example.com/a?%66bclid=1", "https://example.com/a"},
+		{"other parameters kept in order", "https://example.com/a?b=2&fbclid=1&a=%2F", "https://example.com/a?b=2&a=%2F"},
+		{"empty pairs dropped", "https://example.com/a?&id=1&&", "https://example.com/a?id=1"},
+		{"fragment kept", "https://example.com/a?gclid=1#part", "https://example.com/a#part"},
+		{"relative link", "/a?ref_src=twsrc&id=1", "/a?id=1"},
+		{"unparsable", "https://exa mple.com/?fbclid=1", "https://exa mple.com/?fbclid=1"},
+	}
+	for _, tt := range tests {
+		if got := StripTracking(tt.input); got != tt.want {
+			t.Errorf("%s: StripTracking(%q) = %q, want %q", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
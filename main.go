@@ -3,26 +3,45 @@ package main
 import (
 	"context"
 	"database/sql"
-	"encoding/xml"
+	"errors"
+	"flag"
 	"fmt"
-	"html"
 	"io"
 	"log"
-	"net/http"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/diverdib/gator/internal/config"
 	"github.com/diverdib/gator/internal/database"
+	"github.com/diverdib/gator/internal/htmltext"
+	"github.com/diverdib/gator/internal/urlnorm"
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
 )
 
 type state struct {
-	db  *database.Queries
-	cfg *config.Config
+	db   *database.Queries
+	cfg  *config.Config
+	conn *sql.DB
+}
+
+// withTx runs fn with a state whose queries all go through one
+// transaction, which is committed if fn succeeds and rolled back otherwise.
+func withTx(s *state, fn func(tx *state) error) error {
+	tx, err := s.conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&state{db: s.db.WithTx(tx), cfg: s.cfg, conn: s.conn}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+	return nil
 }
 
 type command struct {
@@ -34,21 +53,6 @@ type commands struct {
 	registeredCommands map[string]func(*state, command) error
 }
 
-type RSSFeed struct {
-	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
-	} `xml:"channel"`
-}
-type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
-}
-
 // register adds a new handler function to the map
 func (c *commands) register(name string, f func(*state, command) error) {
 	if c.registeredCommands == nil {
@@ -66,6 +70,31 @@ func (c *commands) run(s *state, cmd command) error {
 	return handler(s, cmd)
 }
 
+// newFlagSet returns a flag set for a command's options. Errors are
+// reported by the handler, so the flag package's own output is silenced.
+func newFlagSet(cmd command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseArgs parses flags wherever they appear between the positional
+// arguments, which flag.FlagSet alone stops doing at the first positional
+// one, and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
 func handlerLogin(s *state, cmd command) error {
 	if len(cmd.args) != 1 {
 		return fmt.Errorf("usage: %v <name>", cmd.name)
@@ -141,23 +170,45 @@ func handlerGetUsers(s *state, cmd command) error {
 }
 
 func handlerAddFeed(s *state, cmd command, user database.User) error {
-	if len(cmd.args) != 2 {
-		return fmt.Errorf("usage: %s <name> <url>", cmd.name)
+	if len(cmd.args) < 1 || len(cmd.args) > 2 {
+		return fmt.Errorf("usage: %s [name] <url>", cmd.name)
 	}
 
-	name := cmd.args[0]
-	feedURL := cmd.args[1]
+	// Without a name the feed is named after its title
+	name := ""
+	if len(cmd.args) == 2 {
+		name = strings.TrimSpace(cmd.args[0])
+	}
+	feedURL := cmd.args[len(cmd.args)-1]
 
-	feed, err := s.db.CreateFeed(context.Background(), database.CreateFeedParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now().UTC(),
-		UpdatedAt: time.Now().UTC(),
-		Name:      name,
-		Url:       feedURL,
-		UserID:    user.ID,
-	})
+	// The URL may be an alias of a feed that has moved
+	if existing, err := getFeedByURL(s, feedURL); err == nil {
+		return fmt.Errorf("feed %s already exists at %s, use follow to subscribe to it", existing.Name, existing.Url)
+	}
+
+	// Website URLs are resolved to the feeds they announce
+	candidates, err := discoverFeeds(context.Background(), feedURL)
+	if err != nil {
+		return fmt.Errorf("could not find a feed at %s: %w", feedURL, err)
+	}
+	picked, err := pickFeed(candidates)
+	if err != nil {
+		return err
+	}
+	// Only feeds that parse make it into everyone's agg loop
+	picked, err = loadCandidate(context.Background(), picked)
 	if err != nil {
-		return fmt.Errorf("could not create feed: %w", err)
+		return fmt.Errorf("could not add feed: %w", err)
+	}
+	if urlnorm.Key(picked.URL) != urlnorm.Key(feedURL) {
+		fmt.Printf("Using feed %s\n", picked.URL)
+		if existing, err := getFeedByURL(s, picked.URL); err == nil {
+			return fmt.Errorf("feed %s already exists at %s, use follow to subscribe to it", existing.Name, existing.Url)
+		}
+	}
+	feed, err := createFeed(s, user, name, "", picked)
+	if err != nil {
+		return err
 	}
 
 	_, err = s.db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
@@ -179,101 +230,206 @@ func handlerAddFeed(s *state, cmd command, user database.User) error {
 	return nil
 }
 
-func handlerBrowse(s *state, cmd command, user database.User) error {
-	limit := 2
-	if len(cmd.args) == 1 {
-		if l, err := strconv.Atoi(cmd.args[0]); err == nil {
-			limit = l
-		}
+// createFeed stores a feed that was fetched and parsed, along with what it
+// says about itself. Without a name the feed is named after its title, the
+// site URL is only used when the feed doesn't link to its site.
+func createFeed(s *state, user database.User, name, siteURL string, picked feedCandidate) (database.Feed, error) {
+	feedURL := canonicalURL(picked.URL)
+	parsed := picked.Feed
+	if name == "" {
+		name = strings.TrimSpace(parsed.Title)
+	}
+	if name == "" {
+		name = feedHost(feedURL)
 	}
+	if parsed.Link != "" {
+		siteURL = parsed.Link
+	}
+	image := resolveReference(feedURL, parsed.Image)
 
-	posts, err := s.db.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
-		UserID: user.ID,
-		Limit:  int32(limit),
+	feed, err := s.db.CreateFeed(context.Background(), database.CreateFeedParams{
+		ID:          uuid.New(),
+		CreatedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
+		Name:        name,
+		Url:         feedURL,
+		UserID:      user.ID,
+		SiteUrl:     sql.NullString{String: siteURL, Valid: siteURL != ""},
+		Description: sql.NullString{String: parsed.Description, Valid: parsed.Description != ""},
+		Language:    sql.NullString{String: parsed.Language, Valid: parsed.Language != ""},
+		ImageUrl:    sql.NullString{String: image, Valid: image != ""},
+		UrlKey:      urlnorm.Key(feedURL),
 	})
 	if err != nil {
-		return fmt.Errorf("could not get posts: %w", err)
+		return database.Feed{}, fmt.Errorf("could not create feed: %w", err)
 	}
+	return feed, nil
+}
 
-	fmt.Printf("Found %d posts for user %s:\n", len(posts), user.Name)
-	for _, post := range posts {
-		fmt.Printf("%s from %s\n", post.PublishedAt.Time.Format("Mon Jan _2"), post.Title)
-		fmt.Printf("--- %s ---\n", post.Url)
-		fmt.Printf("Description: %v\n\n", post.Description.String)
+func handlerRead(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 1 {
+		return fmt.Errorf("usage: %s <post_id>", cmd.name)
 	}
-	return nil
-}
 
-func scrapeFeeds(s *state) {
-	feed, err := s.db.GetNextFeedToFetch(context.Background())
+	postID, err := uuid.Parse(cmd.args[0])
 	if err != nil {
-		fmt.Println("could not find a feed to fetch: ", err)
-		return
+		return fmt.Errorf("invalid post ID %s: %w", cmd.args[0], err)
 	}
 
-	err = s.db.MarkFeedFetched(context.Background(), database.MarkFeedFetchedParams{
-		ID:            feed.ID,
-		LastFetchedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	post, err := s.db.GetFollowedPost(context.Background(), database.GetFollowedPostParams{
+		UserID: user.ID,
+		ID:     postID,
 	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("post %s isn't in a feed you follow", postID)
+	}
 	if err != nil {
-		fmt.Printf("could not mark feed %s as fetched: %v\n", feed.Name, err)
-		return
+		return fmt.Errorf("could not find post %s: %w", postID, err)
 	}
 
-	rssFeed, err := fetchFeed(context.Background(), feed.Url)
+	err = s.db.MarkPostRead(context.Background(), database.MarkPostReadParams{
+		ReadAt: time.Now().UTC(),
+		UserID: user.ID,
+		PostID: post.ID,
+	})
 	if err != nil {
-		fmt.Printf("could not collect feed %s: %v\n", feed.Name, err)
-		return
-	}
-	fmt.Printf("Found %d posts in feed %s\n", len(rssFeed.Channel.Item), feed.Name)
-	for _, item := range rssFeed.Channel.Item {
-		publishedAt := sql.NullTime{}
-		if item.PubDate != "" {
-			t, err := time.Parse(time.RFC1123Z, item.PubDate)
-			if err != nil {
-				t, err = time.Parse(time.RFC1123, item.PubDate)
-			}
-			if err == nil {
-				publishedAt = sql.NullTime{Time: t, Valid: true}
-			} else {
-				log.Printf("could not parse date %q: %v", item.PubDate, err)
-			}
+		return fmt.Errorf("could not mark post as read: %w", err)
+	}
+
+	fmt.Printf("%s from %s\n", post.PublishedAt.Time.Format("Mon Jan _2"), htmltext.Printable(post.Title))
+	fmt.Printf("--- %s ---\n", post.Url)
+	if post.OriginalUrl.Valid && post.OriginalUrl.String != post.Url {
+		fmt.Printf("Via: %s\n", post.OriginalUrl.String)
+	}
+	if post.EditedAt.Valid {
+		fmt.Printf("Edited: %s\n", post.EditedAt.Time.Format("Mon Jan _2 15:04"))
+	}
+	if post.Author.Valid {
+		fmt.Printf("Author: %s\n", htmltext.Printable(post.Author.String))
+	}
+	if len(post.Categories) > 0 {
+		fmt.Printf("Categories: %s\n", strings.Join(post.Categories, ", "))
+	}
+	if post.CommentsUrl.Valid {
+		fmt.Printf("Comments: %s\n", post.CommentsUrl.String)
+	}
+	if post.EnclosureUrl.Valid {
+		fmt.Printf("Attachment: %s", post.EnclosureUrl.String)
+		if post.EnclosureType.Valid {
+			fmt.Printf(" (%s)", post.EnclosureType.String)
 		}
-		_, err = s.db.CreatePost(context.Background(), database.CreatePostParams{
-			ID:          uuid.New(),
-			CreatedAt:   time.Now().UTC(),
-			UpdatedAt:   time.Now().UTC(),
-			Title:       item.Title,
-			Url:         item.Link,
-			Description: sql.NullString{String: item.Description, Valid: item.Description != ""},
-			PublishedAt: publishedAt,
-			FeedID:      feed.ID,
-		})
+		fmt.Println()
+	}
+	fmt.Println()
+	fmt.Printf("%v\n", htmltext.Render(postBody(post.Content, post.Description)))
+	return nil
+}
+
+// postBody returns the full content of a post if the feed provided it and
+// its description otherwise.
+func postBody(content, description sql.NullString) string {
+	if content.Valid {
+		return content.String
+	}
+	return description.String
+}
+
+func handlerMarkRead(s *state, cmd command, user database.User) error {
+	usage := fmt.Errorf("usage: %s --feed <url> | --all | --before <date>", cmd.name)
+
+	fs := newFlagSet(cmd)
+	feedURL := fs.String("feed", "", "only mark posts of this feed")
+	all := fs.Bool("all", false, "mark every post as read")
+	before := fs.String("before", "", "only mark posts published before this date")
+	args, err := parseArgs(fs, cmd.args)
+	if err != nil || len(args) > 0 {
+		return usage
+	}
+	if !*all && *feedURL == "" && *before == "" {
+		return usage
+	}
+
+	params := database.MarkPostsReadParams{
+		ReadAt: time.Now().UTC(),
+		UserID: user.ID,
+	}
+	if *feedURL != "" {
+		feed, err := getFeedByURL(s, *feedURL)
+		if err != nil {
+			return fmt.Errorf("could not find feed with URL %s: %w", *feedURL, err)
+		}
+		params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+	if *before != "" {
+		t, err := parseDateArg(*before)
 		if err != nil {
-			if strings.Contains(err.Error(), "duplicate key") {
-				continue
-			}
-			log.Printf("could not create post: %v", err)
+			return err
 		}
+		params.Before = sql.NullTime{Time: t, Valid: true}
+	}
+
+	marked, err := s.db.MarkPostsRead(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("could not mark posts as read: %w", err)
 	}
+	fmt.Printf("Marked %d posts as read\n", marked)
+	return nil
+}
+
+// parseDateArg parses a date given on the command line, either as a day
+// (2006-01-02) or as a full RFC 3339 timestamp.
+func parseDateArg(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD or RFC 3339", value)
+	}
+	return t.UTC(), nil
 }
 
 func handlerAgg(s *state, cmd command) error {
-	if len(cmd.args) != 1 {
-		return fmt.Errorf("usage: %s <time_between_reqs>", cmd.name)
+	usage := fmt.Errorf("usage: %s <time_between_reqs> [--workers n] [--batch n] [--per-host n] [--disable-after n] [--revisions] [--resolve-links]", cmd.name)
+
+	fs := newFlagSet(cmd)
+	workers := fs.Int("workers", 1, "number of feeds fetched concurrently")
+	batch := fs.Int("batch", 1, "number of feeds claimed per tick")
+	perHost := fs.Int("per-host", 2, "maximum concurrent requests per host")
+	disableAfter := fs.Int("disable-after", 10, "consecutive failures after which a feed is disabled, 0 to never disable")
+	revisions := fs.Bool("revisions", false, "keep the previous version of posts that change")
+	resolveLinks := fs.Bool("resolve-links", false, "unwrap redirect links and strip tracking parameters from post URLs")
+	args, err := parseArgs(fs, cmd.args)
+	if err != nil || len(args) != 1 {
+		return usage
 	}
 
-	timeBetweenRequests, err := time.ParseDuration(cmd.args[0])
+	timeBetweenRequests, err := time.ParseDuration(args[0])
 	if err != nil {
 		return fmt.Errorf("invalid duration: %w", err)
 	}
+	if *workers < 1 || *batch < 1 || *perHost < 1 {
+		return fmt.Errorf("--workers, --batch and --per-host must be at least 1")
+	}
+	if *disableAfter < 0 {
+		return fmt.Errorf("--disable-after must not be negative")
+	}
 
-	fmt.Printf("Collecting feeds every %s\n", timeBetweenRequests)
+	opts := aggOptions{
+		workers:      *workers,
+		batch:        *batch,
+		perHost:      *perHost,
+		disableAfter: *disableAfter,
+		revisions:    *revisions,
+		resolveLinks: *resolveLinks,
+	}
+
+	fmt.Printf("Collecting %d feeds every %s with %d workers\n", opts.batch, timeBetweenRequests, opts.workers)
 
 	ticker := time.NewTicker(timeBetweenRequests)
 
 	for ; ; <-ticker.C {
-		scrapeFeeds(s)
+		scrapeFeeds(s, opts)
 	}
 }
 
@@ -298,6 +454,134 @@ func handlerGetFeed(s *state, cmd command) error {
 	return nil
 }
 
+func handlerFeedStatus(s *state, cmd command) error {
+	fs := newFlagSet(cmd)
+	slowAfter := fs.Duration("slow", 3*time.Second, "response time above which a feed is slow")
+	staleAfter := fs.Duration("stale", 7*24*time.Hour, "time without a successful fetch after which a feed is stale")
+	all := fs.Bool("all", false, "also list healthy feeds")
+	if args, err := parseArgs(fs, cmd.args); err != nil || len(args) > 0 {
+		return fmt.Errorf("usage: %s [--slow 3s] [--stale 168h] [--all]", cmd.name)
+	}
+
+	feeds, err := s.db.GetFeeds(context.Background())
+	if err != nil {
+		return fmt.Errorf("could not get feeds: %w", err)
+	}
+
+	now := time.Now().UTC()
+	listed := 0
+	for _, feed := range feeds {
+		problems := feedProblems(feed, now, *slowAfter, *staleAfter)
+		if len(problems) == 0 && !*all {
+			continue
+		}
+		listed++
+
+		status := "healthy"
+		if len(problems) > 0 {
+			status = strings.Join(problems, ", ")
+		}
+		lastSuccess := "never"
+		if feed.LastSuccessAt.Valid {
+			lastSuccess = feed.LastSuccessAt.Time.Format(time.DateTime)
+		}
+
+		fmt.Printf("* Name:			%s\n", feed.Name)
+		fmt.Printf("* URL:			 %s\n", feed.Url)
+		fmt.Printf("* Status:		  %s\n", status)
+		if feed.DisabledReason.Valid {
+			fmt.Printf("* Disabled:		%s\n", feed.DisabledReason.String)
+		}
+		fmt.Printf("* Last Success:	%s\n", lastSuccess)
+		if feed.LastStatus.Valid {
+			fmt.Printf("* HTTP Status:	 %d\n", feed.LastStatus.Int32)
+		}
+		if feed.LastError.Valid {
+			fmt.Printf("* Last Error:	  %s\n", feed.LastError.String)
+		}
+		fmt.Printf("* Failures:		%d\n", feed.ConsecutiveFailures)
+		if feed.ResponseTimeMs.Valid {
+			fmt.Printf("* Response Time:   %dms\n", feed.ResponseTimeMs.Int32)
+		}
+		if feed.ItemCount.Valid {
+			fmt.Printf("* Items:		   %d\n", feed.ItemCount.Int32)
+		}
+		fmt.Println("--------------------")
+	}
+
+	if listed == 0 {
+		fmt.Printf("All %d feeds are healthy.\n", len(feeds))
+	}
+	return nil
+}
+
+// feedProblems lists why a feed needs attention: it is failing, it
+// responds slowly, or it hasn't been fetched successfully in a while.
+func feedProblems(feed database.GetFeedsRow, now time.Time, slowAfter, staleAfter time.Duration) []string {
+	var problems []string
+	if !feed.Enabled {
+		problems = append(problems, "disabled")
+	}
+	if feed.ConsecutiveFailures > 0 {
+		problems = append(problems, "broken")
+	}
+	if feed.ResponseTimeMs.Valid && time.Duration(feed.ResponseTimeMs.Int32)*time.Millisecond > slowAfter {
+		problems = append(problems, "slow")
+	}
+	// Feeds that were never attempted aren't stale yet, just new
+	if feed.LastFetchedAt.Valid && (!feed.LastSuccessAt.Valid || now.Sub(feed.LastSuccessAt.Time) > staleAfter) {
+		problems = append(problems, "stale")
+	}
+	return problems
+}
+
+func handlerFeed(s *state, cmd command) error {
+	usage := fmt.Errorf("usage: %s <enable|disable> <url> [reason]", cmd.name)
+	if len(cmd.args) < 2 {
+		return usage
+	}
+
+	action := cmd.args[0]
+	url := cmd.args[1]
+
+	feed, err := getFeedByURL(s, url)
+	if err != nil {
+		return fmt.Errorf("could not find feed with URL %s: %w", url, err)
+	}
+
+	switch action {
+	case "enable":
+		if len(cmd.args) != 2 {
+			return usage
+		}
+		err = s.db.EnableFeed(context.Background(), database.EnableFeedParams{
+			ID:        feed.ID,
+			UpdatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("could not enable feed: %w", err)
+		}
+		fmt.Printf("Feed %s is enabled and will be fetched on the next run\n", feed.Name)
+	case "disable":
+		reason := "disabled manually"
+		if len(cmd.args) > 2 {
+			reason = strings.Join(cmd.args[2:], " ")
+		}
+		err = s.db.DisableFeed(context.Background(), database.DisableFeedParams{
+			ID:             feed.ID,
+			DisabledReason: sql.NullString{String: reason, Valid: true},
+			UpdatedAt:      time.Now().UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("could not disable feed: %w", err)
+		}
+		fmt.Printf("Feed %s is disabled: %s\n", feed.Name, reason)
+	default:
+		return usage
+	}
+	return nil
+}
+
 func handlerFollow(s *state, cmd command, user database.User) error {
 	if len(cmd.args) != 1 {
 		return fmt.Errorf("usage: %s <url>", cmd.name)
@@ -305,7 +589,7 @@ func handlerFollow(s *state, cmd command, user database.User) error {
 
 	url := cmd.args[0]
 
-	feed, err := s.db.GetFeedByUrl(context.Background(), url)
+	feed, err := getFeedByURL(s, url)
 	if err != nil {
 		return fmt.Errorf("could not find feed with URL %s: %w", url, err)
 	}
@@ -336,8 +620,23 @@ func handlerFollowing(s *state, cmd command, user database.User) error {
 	}
 
 	fmt.Printf("Feeds followed by %s:\n", user.Name)
-	for _, f := range feeds {
-		fmt.Printf("* %s\n", f.FeedName)
+	tags, groups := followsByTag(feeds)
+	if len(tags) == 1 && tags[0] == "" {
+		for _, f := range feeds {
+			fmt.Printf("* %s (%d unread)\n", f.FeedName, f.UnreadCount)
+		}
+		return nil
+	}
+
+	for _, tag := range tags {
+		if tag == "" {
+			fmt.Println("Untagged:")
+		} else {
+			fmt.Printf("%s:\n", tag)
+		}
+		for _, f := range groups[tag] {
+			fmt.Printf("  * %s (%d unread)\n", f.FeedName, f.UnreadCount)
+		}
 	}
 
 	return nil
@@ -353,6 +652,7 @@ func handlerUnfollow(s *state, cmd command, user database.User) error {
 	result, err := s.db.DeleteFeedFollow(context.Background(), database.DeleteFeedFollowParams{
 		UserID: user.ID,
 		Url:    url,
+		UrlKey: urlnorm.Key(url),
 	})
 	if err != nil {
 		return fmt.Errorf("could not unfollow feed: %w", err)
@@ -379,6 +679,18 @@ func printFeed(feed database.Feed) {
 	fmt.Printf("* Name:			%s\n", feed.Name)
 	fmt.Printf("* URL:			 %s\n", feed.Url)
 	fmt.Printf("* User ID:		 %s\n", feed.UserID)
+	if feed.SiteUrl.Valid {
+		fmt.Printf("* Site:			%s\n", feed.SiteUrl.String)
+	}
+	if feed.Description.Valid {
+		fmt.Printf("* Description:	 %s\n", htmltext.Render(feed.Description.String))
+	}
+	if feed.Language.Valid {
+		fmt.Printf("* Language:		%s\n", feed.Language.String)
+	}
+	if feed.ImageUrl.Valid {
+		fmt.Printf("* Image:		   %s\n", feed.ImageUrl.String)
+	}
 }
 
 func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
@@ -396,59 +708,6 @@ func middlewareLoggedIn(handler func(s *state, cmd command, user database.User)
 	}
 }
 
-func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	// Set the User-Agent header
-	req.Header.Set("User-Agent", "gator")
-
-	// Make the HTTP request
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	// Check for non-200 status codes
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch feed: status code %d", resp.StatusCode)
-	}
-
-	// Read the data and Unmarshal into RSSFeed struct
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var feed RSSFeed
-	err = xml.Unmarshal(data, &feed)
-	if err != nil {
-		return nil, err
-	}
-
-	// Unescape the top level Channel fields
-	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
-	feed.Channel.Link = html.UnescapeString(feed.Channel.Link)
-	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
-
-	// Unescape each Item's fields
-	for i := range feed.Channel.Item {
-		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
-		feed.Channel.Item[i].Link = html.UnescapeString(feed.Channel.Item[i].Link)
-		feed.Channel.Item[i].Description = html.UnescapeString(feed.Channel.Item[i].Description)
-		feed.Channel.Item[i].PubDate = html.UnescapeString(feed.Channel.Item[i].PubDate)
-	}
-	fmt.Println("Feed fetched successfully:", feed.Channel.Title)
-	// Print the entire structure to the console
-	//fmt.Printf("%+v\n", feed)
-
-	return &feed, nil
-}
-
 func main() {
 
 	// Read the config file
@@ -466,8 +725,9 @@ func main() {
 
 	// Create the program state
 	programState := &state{
-		db:  dbQueries,
-		cfg: &cfg,
+		db:   dbQueries,
+		cfg:  &cfg,
+		conn: db,
 	}
 
 	cmds := commands{
@@ -481,10 +741,24 @@ func main() {
 	cmds.register("agg", handlerAgg)
 	cmds.register("addfeed", middlewareLoggedIn(handlerAddFeed))
 	cmds.register("feeds", handlerGetFeed)
+	cmds.register("feedstatus", handlerFeedStatus)
+	cmds.register("feed", handlerFeed)
+	cmds.register("canonicalize", handlerCanonicalize)
 	cmds.register("follow", middlewareLoggedIn(handlerFollow))
 	cmds.register("following", middlewareLoggedIn(handlerFollowing))
+	cmds.register("tag", middlewareLoggedIn(handlerTag))
 	cmds.register("unfollow", middlewareLoggedIn(handlerUnfollow))
 	cmds.register("browse", middlewareLoggedIn(handlerBrowse))
+	cmds.register("read", middlewareLoggedIn(handlerRead))
+	cmds.register("revisions", middlewareLoggedIn(handlerRevisions))
+	cmds.register("mark-read", middlewareLoggedIn(handlerMarkRead))
+	cmds.register("star", middlewareLoggedIn(handlerStar))
+	cmds.register("unstar", middlewareLoggedIn(handlerUnstar))
+	cmds.register("starred", middlewareLoggedIn(handlerStarred))
+	cmds.register("search", middlewareLoggedIn(handlerSearch))
+	cmds.register("tui", middlewareLoggedIn(handlerTUI))
+	cmds.register("import", middlewareLoggedIn(handlerImport))
+	cmds.register("export", middlewareLoggedIn(handlerExport))
 
 	// Check if enough argumaents were provided
 	if len(os.Args) < 2 {
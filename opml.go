@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/diverdib/gator/internal/database"
+	"github.com/diverdib/gator/internal/urlnorm"
+	"github.com/google/uuid"
+)
+
+// OPML is an OPML 1.0/2.0 subscription list.
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    struct {
+		Title       string `xml:"title"`
+		DateCreated string `xml:"dateCreated,omitempty"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []OPMLOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+// OPMLOutline is either a subscription (it has an xmlUrl) or a folder
+// holding further outlines.
+type OPMLOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Category string        `xml:"category,attr,omitempty"`
+	Outlines []OPMLOutline `xml:"outline"`
+}
+
+// opmlSubscription is a flattened subscription together with its tags:
+// the path of the folder it was nested in, like "tech/go", and the
+// categories it lists.
+type opmlSubscription struct {
+	Name    string
+	XMLURL  string
+	HTMLURL string
+	Tags    []string
+}
+
+func parseOPML(data []byte) ([]opmlSubscription, error) {
+	var doc OPML
+	err := decodeXML(data, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OPML: %w", err)
+	}
+
+	var subs []opmlSubscription
+	var walk func(outlines []OPMLOutline, folder string)
+	walk = func(outlines []OPMLOutline, folder string) {
+		for _, o := range outlines {
+			name := strings.TrimSpace(o.Title)
+			if name == "" {
+				name = strings.TrimSpace(o.Text)
+			}
+			if o.XMLURL != "" {
+				if name == "" {
+					name = o.XMLURL
+				}
+				var tags []string
+				if folder != "" {
+					tags = append(tags, folder)
+				}
+				// Categories are comma separated paths like "/tech/go"
+				for _, category := range strings.Split(o.Category, ",") {
+					if category = strings.Trim(strings.TrimSpace(category), "/"); category != "" {
+						tags = append(tags, category)
+					}
+				}
+				subs = append(subs, opmlSubscription{
+					Name:    name,
+					XMLURL:  strings.TrimSpace(o.XMLURL),
+					HTMLURL: strings.TrimSpace(o.HTMLURL),
+					Tags:    tags,
+				})
+			}
+			if len(o.Outlines) > 0 {
+				walk(o.Outlines, joinFolder(folder, name))
+			}
+		}
+	}
+	walk(doc.Body.Outlines, "")
+	return subs, nil
+}
+
+// joinFolder returns the path of a folder nested in another one, folders
+// at the top level have no parent.
+func joinFolder(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "/" + name
+}
+
+// parentFolder returns the path of the folder a folder is nested in, or ""
+// for folders at the top level.
+func parentFolder(folder string) string {
+	i := strings.LastIndex(folder, "/")
+	if i < 0 {
+		return ""
+	}
+	return folder[:i]
+}
+
+func handlerImport(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 2 || cmd.args[0] != "opml" {
+		return fmt.Errorf("usage: %s opml <file>", cmd.name)
+	}
+
+	data, err := os.ReadFile(cmd.args[1])
+	if err != nil {
+		return fmt.Errorf("could not read %s: %w", cmd.args[1], err)
+	}
+
+	subs, err := parseOPML(data)
+	if err != nil {
+		return err
+	}
+
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("could not get follows for user %s: %w", user.Name, err)
+	}
+	followed := make(map[uuid.UUID]bool)
+	for _, f := range follows {
+		followed[f.FeedID] = true
+	}
+
+	added, skipped, failed := 0, 0, 0
+	for _, sub := range subs {
+		feed, created, err := findOrCreateFeed(s, user, sub.Name, sub.XMLURL, sub.HTMLURL)
+		if err != nil {
+			fmt.Printf("! %s (%s): %v\n", sub.Name, sub.XMLURL, err)
+			failed++
+			continue
+		}
+
+		if followed[feed.ID] {
+			fmt.Printf("- %s (already following)\n", feed.Name)
+			tagImportedFollow(s, user, feed, sub.Tags)
+			skipped++
+			continue
+		}
+
+		_, err = s.db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+		})
+		if err != nil {
+			fmt.Printf("! %s (%s): could not follow feed: %v\n", sub.Name, sub.XMLURL, err)
+			failed++
+			continue
+		}
+		followed[feed.ID] = true
+
+		if created {
+			fmt.Printf("+ %s (new feed)\n", feed.Name)
+		} else {
+			fmt.Printf("+ %s\n", feed.Name)
+		}
+		tagImportedFollow(s, user, feed, sub.Tags)
+		added++
+	}
+
+	fmt.Printf("Imported %d feeds: %d added, %d skipped, %d failed\n", len(subs), added, skipped, failed)
+	return nil
+}
+
+// tagImportedFollow adds the tags of a subscription to the follow, on top
+// of the tags it already has.
+func tagImportedFollow(s *state, user database.User, feed database.Feed, subTags []string) {
+	tags := cleanTags(subTags)
+	if len(tags) == 0 {
+		return
+	}
+	err := s.db.AddFollowTags(context.Background(), database.AddFollowTagsParams{
+		Tags:      tags,
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		fmt.Printf("! %s: could not tag feed: %v\n", feed.Name, err)
+	}
+}
+
+// findOrCreateFeed looks a feed up by URL, including moved feed aliases,
+// and creates it if nobody has added it yet. Like addfeed, new feeds are
+// fetched first so only working feeds are created, with their metadata.
+func findOrCreateFeed(s *state, user database.User, name, feedURL, siteURL string) (database.Feed, bool, error) {
+	feed, err := getFeedByURL(s, feedURL)
+	if err == nil {
+		return feed, false, nil
+	}
+
+	picked, err := loadCandidate(context.Background(), feedCandidate{URL: feedURL})
+	if err != nil {
+		return database.Feed{}, false, err
+	}
+	// The URL may redirect to a feed that was already added
+	if urlnorm.Key(picked.URL) != urlnorm.Key(feedURL) {
+		if feed, err := getFeedByURL(s, picked.URL); err == nil {
+			return feed, false, nil
+		}
+	}
+
+	feed, err = createFeed(s, user, name, siteURL, picked)
+	if err != nil {
+		return database.Feed{}, false, err
+	}
+	return feed, true, nil
+}
+
+func handlerExport(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 1 || len(cmd.args) > 2 || cmd.args[0] != "opml" {
+		return fmt.Errorf("usage: %s opml [file]", cmd.name)
+	}
+
+	feeds, err := s.db.GetFollowedFeeds(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("could not get feeds for user %s: %w", user.Name, err)
+	}
+
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("could not get follows for user %s: %w", user.Name, err)
+	}
+	tagsByFeed := make(map[uuid.UUID][]string)
+	for _, f := range follows {
+		tagsByFeed[f.FeedID] = f.Tags
+	}
+
+	// Each feed is listed once, in the folder of its first tag, so other
+	// readers don't subscribe to it twice. Tags like "tech/go" are nested
+	// folders, and all tags of a feed are kept as its categories.
+	var untagged []OPMLOutline
+	feedsByFolder := make(map[string][]OPMLOutline)
+	subfolders := make(map[string][]string)
+	for _, feed := range feeds {
+		outline := OPMLOutline{
+			Text:    feed.Name,
+			Title:   feed.Name,
+			Type:    "rss",
+			XMLURL:  feed.Url,
+			HTMLURL: feed.SiteUrl.String,
+		}
+		tags := tagsByFeed[feed.ID]
+		if len(tags) == 0 {
+			untagged = append(untagged, outline)
+			continue
+		}
+		outline.Category = "/" + strings.Join(tags, ",/")
+
+		folder := tags[0]
+		feedsByFolder[folder] = append(feedsByFolder[folder], outline)
+		// Register the folder and the ones it is nested in
+		for folder != "" {
+			parent := parentFolder(folder)
+			if slices.Contains(subfolders[parent], folder) {
+				break
+			}
+			subfolders[parent] = append(subfolders[parent], folder)
+			folder = parent
+		}
+	}
+
+	var folderOutlines func(parent string) []OPMLOutline
+	folderOutlines = func(parent string) []OPMLOutline {
+		var outlines []OPMLOutline
+		slices.Sort(subfolders[parent])
+		for _, folder := range subfolders[parent] {
+			name := strings.TrimPrefix(folder, parent+"/")
+			if parent == "" {
+				name = folder
+			}
+			outlines = append(outlines, OPMLOutline{
+				Text:     name,
+				Title:    name,
+				Outlines: append(folderOutlines(folder), feedsByFolder[folder]...),
+			})
+		}
+		return outlines
+	}
+
+	var doc OPML
+	doc.Version = "2.0"
+	doc.Head.Title = fmt.Sprintf("gator subscriptions of %s", user.Name)
+	doc.Head.DateCreated = time.Now().UTC().Format(time.RFC1123Z)
+	doc.Body.Outlines = append(folderOutlines(""), untagged...)
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode OPML: %w", err)
+	}
+
+	document := fmt.Sprintf("%s%s\n", xml.Header, data)
+
+	// Without a file the document goes to stdout so it can be piped
+	if len(cmd.args) == 1 {
+		if _, err := io.WriteString(os.Stdout, document); err != nil {
+			return fmt.Errorf("could not write OPML: %w", err)
+		}
+		return nil
+	}
+
+	// WriteFile also reports a failed close, where a full disk can show up
+	if err := os.WriteFile(cmd.args[1], []byte(document), 0o644); err != nil {
+		return fmt.Errorf("could not write %s: %w", cmd.args[1], err)
+	}
+	fmt.Printf("Exported %d feeds to %s\n", len(feeds), cmd.args[1])
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/diverdib/gator/internal/urlnorm"
+)
+
+// wrapperDomains only redirect to the link they wrap. Newsletter services
+// give every sender a subdomain, so subdomains match as well.
+var wrapperDomains = []string{
+	"feedproxy.google.com",
+	"feeds.feedburner.com",
+	"t.co",
+	"bit.ly",
+	"buff.ly",
+	"ow.ly",
+	"dlvr.it",
+	"lnkd.in",
+	"trib.al",
+	"list-manage.com",      // Mailchimp
+	"mailchi.mp",           // Mailchimp
+	"ct.sendgrid.net",      // SendGrid
+	"hubspotlinks.com",     // HubSpot
+	"convertkit-mail.com",  // ConvertKit
+	"convertkit-mail2.com", // ConvertKit
+	"mail.beehiiv.com",     // beehiiv
+	"rs6.net",              // Constant Contact
+}
+
+// isWrapper reports whether a link goes through a redirector instead of
+// pointing at the article itself.
+func isWrapper(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range wrapperDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	// Substack routes the links in posts through the publication's domain
+	return strings.HasSuffix(host, ".substack.com") && strings.HasPrefix(u.Path, "/redirect/")
+}
+
+// Resolving links costs a request per redirector, so each feed gets a
+// budget. Links left over when it runs out are resolved on a later fetch.
+const (
+	resolveRequestTimeout = 5 * time.Second
+	resolveFeedTimeout    = 30 * time.Second
+	resolveFeedRequests   = 50
+)
+
+// errResolveBudget is returned once a feed used up its resolve budget.
+var errResolveBudget = errors.New("out of link resolving budget")
+
+// linkResolver unwraps the post links of one feed. Its requests take
+// slots from the host limiter like feed fetches do, and it stops once the
+// feed's budget of requests or time is used up.
+type linkResolver struct {
+	ctx       context.Context
+	limiter   *hostLimiter
+	client    *http.Client
+	requests  int
+	exhausted bool
+}
+
+func newLinkResolver(ctx context.Context, limiter *hostLimiter) *linkResolver {
+	return &linkResolver{
+		ctx:     ctx,
+		limiter: limiter,
+		client: &http.Client{
+			Timeout: resolveRequestTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		requests: resolveFeedRequests,
+	}
+}
+
+// resolve unwraps a post link that goes through redirectors, stopping at
+// the first URL that isn't one so articles themselves aren't fetched, and
+// strips tracking parameters from the result. It reports false if a
+// redirector couldn't be reached, the link is then worth another try.
+func (r *linkResolver) resolve(link string) (string, bool) {
+	current := link
+	resolved := true
+	for range maxRedirects {
+		if !isWrapper(current) {
+			break
+		}
+		next, err := r.nextHop(current)
+		if err != nil {
+			resolved = false
+			break
+		}
+		if next == "" {
+			break
+		}
+		current = next
+	}
+	return canonicalURL(urlnorm.StripTracking(current)), resolved
+}
+
+// nextHop returns where a redirector sends a link, or "" if it answers
+// without a redirect. Some only answer GET requests, HEAD is tried first
+// to skip the body.
+func (r *linkResolver) nextHop(link string) (string, error) {
+	host := feedHost(link)
+	var resp *http.Response
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		if r.requests == 0 || r.ctx.Err() != nil {
+			r.exhausted = true
+			return "", errResolveBudget
+		}
+		r.requests--
+
+		req, err := http.NewRequestWithContext(r.ctx, method, link, nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("User-Agent", "gator")
+
+		r.limiter.acquire(host)
+		resp, err = r.client.Do(req)
+		r.limiter.release(host)
+		if err != nil {
+			if r.ctx.Err() != nil {
+				r.exhausted = true
+			}
+			return "", err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusMethodNotAllowed && resp.StatusCode != http.StatusNotImplemented {
+			break
+		}
+	}
+
+	// Server errors may pass, unlike a redirector that doesn't redirect
+	if resp.StatusCode >= 500 {
+		return "", fmt.Errorf("%s answered %s", link, resp.Status)
+	}
+	location := resp.Header.Get("Location")
+	if resp.StatusCode < 300 || resp.StatusCode > 399 || location == "" {
+		return "", nil
+	}
+	next, err := resp.Request.URL.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	return next.String(), nil
+}
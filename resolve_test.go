@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestIsWrapper(t *testing.T) {
+	tests := []struct {
+		link string
+		want bool
+	}{
+		{"http://feedproxy.google.com/~r/example/~3/abc/post", true},
+		{"https://feeds.feedburner.com/~r/example/~3/abc", true},
+		{"https://t.co/abc123", true},
+		{"https://BIT.LY/abc", true},
+		{"https://us1.list-manage.com/track/click?u=1&id=2", true},
+		{"https://mailchi.mp/example/issue", true},
+		{"https://u123.ct.sendgrid.net/ls/click?upn=x", true},
+		{"https://example.substack.com/redirect/abc?j=x", true},
+		{"https://example.substack.com/p/a-post", false},
+		{"https://substack.com/redirect/abc", false},
+		{"https://notbit.ly/abc", false},
+		{"https://bit.ly.example.com/abc", false},
+		{"https://example.com/t.co", false},
+		{"https://example.com/post", false},
+		{"/relative/post", false},
+		{"", false},
+		{"://not a url", false},
+	}
+	for _, tt := range tests {
+		if got := isWrapper(tt.link); got != tt.want {
+			t.Errorf("isWrapper(%q) = %v, want %v", tt.link, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/diverdib/gator/internal/database"
+	"github.com/diverdib/gator/internal/htmltext"
+	"github.com/google/uuid"
+)
+
+func handlerRevisions(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 1 {
+		return fmt.Errorf("usage: %s <post_id>", cmd.name)
+	}
+
+	postID, err := uuid.Parse(cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("invalid post ID %s: %w", cmd.args[0], err)
+	}
+
+	post, err := s.db.GetFollowedPost(context.Background(), database.GetFollowedPostParams{
+		UserID: user.ID,
+		ID:     postID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("post %s isn't in a feed you follow", postID)
+	}
+	if err != nil {
+		return fmt.Errorf("could not find post %s: %w", postID, err)
+	}
+
+	revisions, err := s.db.GetPostRevisions(context.Background(), post.ID)
+	if err != nil {
+		return fmt.Errorf("could not get revisions: %w", err)
+	}
+
+	if len(revisions) == 0 {
+		// Revisions are only kept by agg --revisions
+		fmt.Printf("No earlier versions of %s were kept\n", htmltext.Printable(post.Title))
+		return nil
+	}
+
+	fmt.Printf("%d earlier versions of %s:\n", len(revisions), htmltext.Printable(post.Title))
+	for _, revision := range revisions {
+		fmt.Printf("Replaced %s: %s\n", revision.CreatedAt.Format("Mon Jan _2 15:04"), htmltext.Printable(revision.Title))
+		fmt.Printf("%v\n", htmltext.Render(postBody(revision.Content, revision.Description)))
+		fmt.Println("--------------------")
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/diverdib/gator/internal/database"
+)
+
+const (
+	// defaultFetchInterval is used when a feed gives no hint of its own
+	defaultFetchInterval = 30 * time.Minute
+	// minFetchInterval and maxFetchInterval bound the hints a feed can give
+	minFetchInterval = 5 * time.Minute
+	maxFetchInterval = 24 * time.Hour
+	// claimLease keeps a claimed feed from being claimed again while it is
+	// being fetched, and lets it be retried if the process dies
+	claimLease = 10 * time.Minute
+)
+
+// scheduleNextFetch stores when a feed is due again based on the outcome
+// of the fetch that just finished.
+func scheduleNextFetch(s *state, feed database.Feed, result *fetchResult, fetchErr error) {
+	failures := int32(0)
+	if fetchErr != nil {
+		failures = feed.ConsecutiveFailures + 1
+	}
+
+	next := nextFetchTime(time.Now().UTC(), feed, result, fetchErr, int(failures))
+	err := s.db.ScheduleFeedFetch(context.Background(), database.ScheduleFeedFetchParams{
+		ID:                  feed.ID,
+		NextFetchAt:         sql.NullTime{Time: next, Valid: true},
+		ConsecutiveFailures: failures,
+	})
+	if err != nil {
+		log.Printf("could not schedule next fetch of feed %s: %v", feed.Name, err)
+	}
+}
+
+// nextFetchTime works out when a feed should be fetched again. Successful
+// fetches honour the feed's <ttl> and the Cache-Control max-age, failures
+// back off exponentially, and Retry-After is always respected. A 304 has
+// no feed to read hints from, the ones stored with the feed are used.
+func nextFetchTime(now time.Time, feed database.Feed, result *fetchResult, fetchErr error, failures int) time.Time {
+	if fetchErr != nil {
+		delay := backoff(failures)
+		var statusErr *statusError
+		if errors.As(fetchErr, &statusErr) {
+			delay = max(delay, statusErr.RetryAfter)
+		}
+		return now.Add(delay)
+	}
+
+	ttl, skipHours, skipDays := storedHints(feed)
+	if result.Feed != nil {
+		ttl, skipHours, skipDays = result.Feed.TTL, result.Feed.SkipHours, result.Feed.SkipDays
+	}
+
+	interval := defaultFetchInterval
+	if ttl > 0 {
+		interval = ttl
+	} else if result.MaxAge > 0 {
+		interval = result.MaxAge
+	}
+	interval = min(max(interval, minFetchInterval), maxFetchInterval)
+	interval = max(interval, result.RetryAfter)
+
+	return skipBlockedHours(now.Add(interval), skipHours, skipDays)
+}
+
+// storedHints returns the <ttl>, <skipHours> and <skipDays> stored with a
+// feed at its last full fetch.
+func storedHints(feed database.Feed) (time.Duration, []int, []time.Weekday) {
+	var ttl time.Duration
+	if feed.TtlMinutes.Valid {
+		ttl = time.Duration(feed.TtlMinutes.Int32) * time.Minute
+	}
+	var skipHours []int
+	for _, h := range feed.SkipHours {
+		skipHours = append(skipHours, int(h))
+	}
+	var skipDays []time.Weekday
+	for _, d := range feed.SkipDays {
+		skipDays = append(skipDays, time.Weekday(d))
+	}
+	return ttl, skipHours, skipDays
+}
+
+// hintColumns converts the scheduling hints of a parsed feed into the form
+// they're stored in.
+func hintColumns(fetched *ParsedFeed) (sql.NullInt32, []int32, []int32) {
+	ttl := sql.NullInt32{Int32: int32(fetched.TTL / time.Minute), Valid: fetched.TTL > 0}
+	skipHours := []int32{}
+	for _, h := range fetched.SkipHours {
+		skipHours = append(skipHours, int32(h))
+	}
+	skipDays := []int32{}
+	for _, d := range fetched.SkipDays {
+		skipDays = append(skipDays, int32(d))
+	}
+	return ttl, skipHours, skipDays
+}
+
+// backoff doubles the retry delay with every consecutive failure, starting
+// at minFetchInterval and capped at maxFetchInterval.
+func backoff(failures int) time.Duration {
+	delay := minFetchInterval
+	for i := 1; i < failures && delay < maxFetchInterval; i++ {
+		delay *= 2
+	}
+	return min(delay, maxFetchInterval)
+}
+
+// skipBlockedHours moves t forward to the first hour that isn't listed in
+// the feed's <skipHours> or <skipDays>, both of which are in GMT.
+func skipBlockedHours(t time.Time, skipHours []int, skipDays []time.Weekday) time.Time {
+	if len(skipHours) == 0 && len(skipDays) == 0 {
+		return t
+	}
+	candidate := t
+	// Give up after a week, a feed that skips every hour is misconfigured
+	for range 7 * 24 {
+		gmt := candidate.UTC()
+		if !slices.Contains(skipHours, gmt.Hour()) && !slices.Contains(skipDays, gmt.Weekday()) {
+			return candidate
+		}
+		candidate = gmt.Truncate(time.Hour).Add(time.Hour)
+	}
+	return t
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/diverdib/gator/internal/database"
+)
+
+func TestNextFetchTime(t *testing.T) {
+	now := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC) // a Monday
+	stored := database.Feed{
+		TtlMinutes: sql.NullInt32{Int32: 24 * 60, Valid: true},
+		SkipHours:  []int32{10, 11},
+		SkipDays:   []int32{int32(time.Saturday)},
+	}
+
+	tests := []struct {
+		name   string
+		feed   database.Feed
+		result *fetchResult
+		want   time.Time
+	}{
+		{
+			"no hints",
+			database.Feed{},
+			&fetchResult{Feed: &ParsedFeed{}},
+			now.Add(defaultFetchInterval),
+		},
+		{
+			"ttl of a full fetch",
+			database.Feed{},
+			&fetchResult{Feed: &ParsedFeed{TTL: 2 * time.Hour}},
+			now.Add(2 * time.Hour),
+		},
+		{
+			"full fetch replaces stored hints",
+			stored,
+			&fetchResult{Feed: &ParsedFeed{TTL: 2 * time.Hour}},
+			now.Add(2 * time.Hour),
+		},
+		{
+			"not modified keeps the stored ttl and skip hours",
+			stored,
+			&fetchResult{NotModified: true},
+			now.Add(26 * time.Hour),
+		},
+		{
+			"not modified without stored hints uses max-age",
+			database.Feed{},
+			&fetchResult{NotModified: true, MaxAge: time.Hour},
+			now.Add(time.Hour),
+		},
+		{
+			"not modified skips stored days",
+			database.Feed{
+				TtlMinutes: sql.NullInt32{Int32: 24 * 60, Valid: true},
+				SkipDays:   []int32{int32(time.Tuesday), int32(time.Wednesday)},
+			},
+			&fetchResult{NotModified: true},
+			time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		if got := nextFetchTime(now, tt.feed, tt.result, nil, 0); !got.Equal(tt.want) {
+			t.Errorf("%s: nextFetchTime = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
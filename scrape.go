@@ -0,0 +1,473 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diverdib/gator/internal/database"
+	"github.com/diverdib/gator/internal/pubdate"
+	"github.com/diverdib/gator/internal/urlnorm"
+	"github.com/google/uuid"
+)
+
+// aggOptions controls how many feeds a single aggregation tick claims
+// and how many of them are fetched at the same time.
+type aggOptions struct {
+	workers      int
+	batch        int
+	perHost      int
+	disableAfter int
+	// revisions keeps the previous version of posts whose content changed
+	revisions bool
+	// resolveLinks unwraps redirect links and strips tracking parameters
+	resolveLinks bool
+}
+
+// scrapeFeeds claims a batch of feeds that are due and fetches them with
+// a pool of workers. Claiming marks the feeds as fetched in the same
+// statement, so concurrent agg processes never pick the same feed.
+func scrapeFeeds(s *state, opts aggOptions) {
+	now := time.Now().UTC()
+	feeds, err := s.db.ClaimFeedsToFetch(context.Background(), database.ClaimFeedsToFetchParams{
+		LastFetchedAt: sql.NullTime{Time: now, Valid: true},
+		NextFetchAt:   sql.NullTime{Time: now.Add(claimLease), Valid: true},
+		Limit:         int32(opts.batch),
+	})
+	if err != nil {
+		fmt.Println("could not claim feeds to fetch: ", err)
+		return
+	}
+	if len(feeds) == 0 {
+		fmt.Println("No feeds are due for fetching")
+		return
+	}
+
+	limiter := newHostLimiter(opts.perHost, feeds)
+
+	var wg sync.WaitGroup
+	for range opts.workers {
+		wg.Go(func() {
+			for {
+				feed, ok := limiter.next()
+				if !ok {
+					return
+				}
+				scrapeFeed(s, feed, limiter, opts)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// scrapeFeed fetches a single feed and stores its items as posts. It's
+// handed the limiter slot of the feed's host, which only covers the fetch.
+func scrapeFeed(s *state, feed database.Feed, limiter *hostLimiter, opts aggOptions) {
+	start := time.Now()
+	result, err := fetchFeed(context.Background(), feed.Url, feed.Etag.String, feed.LastModified.String)
+	// Resolving links takes slots of its own, possibly on this host
+	limiter.release(feedHost(feed.Url))
+	recordFeedHealth(s, feed, result, err, time.Since(start))
+	scheduleNextFetch(s, feed, result, err)
+	if err != nil {
+		fmt.Printf("could not collect feed %s: %v\n", feed.Name, err)
+		disableFailingFeed(s, feed, err, opts.disableAfter)
+		return
+	}
+
+	if result.PermanentURL != "" && canonicalURL(result.PermanentURL) != feed.Url {
+		feed = moveFeed(s, feed, result)
+	}
+
+	if result.NotModified {
+		fmt.Printf("Feed %s has not changed since the last fetch\n", feed.Name)
+		storeCacheHeaders(s, feed, result)
+		return
+	}
+
+	fetched := result.Feed
+	storeFeedMetadata(s, feed, fetched)
+
+	var resolver *linkResolver
+	if opts.resolveLinks {
+		ctx, cancel := context.WithTimeout(context.Background(), resolveFeedTimeout)
+		defer cancel()
+		resolver = newLinkResolver(ctx, limiter)
+	}
+
+	fmt.Printf("Found %d posts in feed %s\n", len(fetched.Items), feed.Name)
+	added, updated, failed, unresolved := 0, 0, 0, 0
+	for _, item := range fetched.Items {
+		key := dedupeKey(item)
+		// Relative links, which Atom allows, resolve against the feed
+		item.Link = resolveReference(feed.Url, item.Link)
+		originalURL := item.Link
+		item.Link = canonicalURL(item.Link)
+		// Without a usable date the post is dated by when it was fetched
+		publishedAt, estimated := time.Now().UTC(), true
+		t, err := pubdate.Parse(item.PubDate)
+		if err == nil {
+			publishedAt, estimated = t, false
+		} else if !errors.Is(err, pubdate.ErrEmpty) {
+			log.Printf("could not parse date of %q: %v", item.Title, err)
+		}
+		// A nil slice would be stored as NULL instead of an empty array
+		categories := item.Categories
+		if categories == nil {
+			categories = []string{}
+		}
+		params := database.UpsertPostParams{
+			ID:                   uuid.New(),
+			CreatedAt:            time.Now().UTC(),
+			UpdatedAt:            time.Now().UTC(),
+			Title:                item.Title,
+			Url:                  item.Link,
+			Description:          sql.NullString{String: item.Description, Valid: item.Description != ""},
+			PublishedAt:          sql.NullTime{Time: publishedAt, Valid: true},
+			FeedID:               feed.ID,
+			Content:              sql.NullString{String: item.Content, Valid: item.Content != ""},
+			Author:               sql.NullString{String: item.Author, Valid: item.Author != ""},
+			Categories:           categories,
+			Guid:                 sql.NullString{String: item.GUID, Valid: item.GUID != ""},
+			CommentsUrl:          sql.NullString{String: item.Comments, Valid: item.Comments != ""},
+			EnclosureUrl:         sql.NullString{String: item.Enclosure.URL, Valid: item.Enclosure.URL != ""},
+			EnclosureType:        sql.NullString{String: item.Enclosure.Type, Valid: item.Enclosure.Type != ""},
+			EnclosureLength:      sql.NullInt64{Int64: item.Enclosure.Length, Valid: item.Enclosure.Length > 0},
+			DedupeKey:            key,
+			PublishedAtEstimated: estimated,
+			OriginalUrl:          sql.NullString{String: originalURL, Valid: originalURL != ""},
+			RevisionID:           uuid.New(),
+			KeepRevision:         opts.revisions,
+		}
+		if resolver != nil {
+			params.Url, params.UrlResolved = resolvePostURL(s, resolver, params)
+			if !params.UrlResolved {
+				unresolved++
+			}
+		}
+
+		post, err := s.db.UpsertPost(context.Background(), params)
+		if errors.Is(err, sql.ErrNoRows) {
+			// The feed already had this item, unchanged
+			continue
+		}
+		if err != nil {
+			log.Printf("could not store post: %v", err)
+			failed++
+			continue
+		}
+		if post.ID == params.ID {
+			added++
+		} else {
+			updated++
+		}
+	}
+	if added > 0 || updated > 0 {
+		fmt.Printf("Stored %d new and %d updated posts of feed %s\n", added, updated, feed.Name)
+	}
+	if resolver != nil && resolver.exhausted {
+		fmt.Printf("Used up the link resolving budget of feed %s, the other links are resolved on the next fetch\n", feed.Name)
+	}
+
+	// Only remember the validators once the items are stored, otherwise a
+	// failed run would be followed by a 304 and the items would be lost.
+	// After a failure they're cleared so the next fetch is a full one,
+	// which also gives links that couldn't be resolved another try.
+	if failed > 0 || unresolved > 0 {
+		storeCacheHeaders(s, feed, &fetchResult{})
+		return
+	}
+	storeCacheHeaders(s, feed, result)
+}
+
+// storeFeedMetadata keeps the site link, description, language, image and
+// scheduling hints of a feed in sync with what the feed says about itself.
+// Fields the feed leaves out keep their stored value, like a site URL from
+// an import.
+func storeFeedMetadata(s *state, feed database.Feed, fetched *ParsedFeed) {
+	orStored := func(stored sql.NullString, value string) sql.NullString {
+		if value == "" {
+			return stored
+		}
+		return sql.NullString{String: value, Valid: true}
+	}
+
+	params := database.SetFeedMetadataParams{
+		ID:          feed.ID,
+		UpdatedAt:   time.Now().UTC(),
+		SiteUrl:     orStored(feed.SiteUrl, fetched.Link),
+		Description: orStored(feed.Description, fetched.Description),
+		Language:    orStored(feed.Language, fetched.Language),
+		ImageUrl:    orStored(feed.ImageUrl, resolveReference(feed.Url, fetched.Image)),
+	}
+	// Scheduling hints follow the feed, a hint it dropped is cleared
+	params.TtlMinutes, params.SkipHours, params.SkipDays = hintColumns(fetched)
+	if params.SiteUrl == feed.SiteUrl && params.Description == feed.Description &&
+		params.Language == feed.Language && params.ImageUrl == feed.ImageUrl &&
+		params.TtlMinutes == feed.TtlMinutes && slices.Equal(params.SkipHours, feed.SkipHours) &&
+		slices.Equal(params.SkipDays, feed.SkipDays) {
+		return
+	}
+
+	err := s.db.SetFeedMetadata(context.Background(), params)
+	if err != nil {
+		log.Printf("could not store metadata of feed %s: %v", feed.Name, err)
+	}
+}
+
+// resolveReference resolves a possibly relative URL found in a feed
+// against the URL of the feed.
+func resolveReference(base, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := b.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return r.String()
+}
+
+// dedupeKey identifies an item within its feed. The GUID is meant for
+// exactly that, items without one are identified by their link as the
+// feed has it, so an edited title is a revision and not a new post. Items
+// without a link either fall back to their title.
+func dedupeKey(item FeedItem) string {
+	if item.GUID != "" {
+		return item.GUID
+	}
+	source := item.Link
+	if source == "" {
+		source = item.Title
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+// resolvePostURL returns the URL of the article a post links to and
+// whether it's fully resolved. Wrapped links are only followed if the post
+// is new, its link changed or it couldn't be resolved before. Links are
+// compared normalized, as older posts stored them.
+func resolvePostURL(s *state, resolver *linkResolver, params database.UpsertPostParams) (string, bool) {
+	if !isWrapper(params.Url) {
+		return canonicalURL(urlnorm.StripTracking(params.Url)), true
+	}
+	old, err := s.db.GetPostByDedupeKey(context.Background(), database.GetPostByDedupeKeyParams{
+		FeedID:    params.FeedID,
+		DedupeKey: params.DedupeKey,
+	})
+	if err == nil && old.UrlResolved && canonicalURL(old.OriginalUrl.String) == params.Url {
+		return old.Url, true
+	}
+	return resolver.resolve(params.Url)
+}
+
+// recordFeedHealth stores the outcome of a fetch so broken and slow feeds
+// show up in the feedstatus command.
+func recordFeedHealth(s *state, feed database.Feed, result *fetchResult, fetchErr error, elapsed time.Duration) {
+	responseTime := sql.NullInt32{Int32: int32(elapsed.Milliseconds()), Valid: true}
+
+	if fetchErr != nil {
+		status := sql.NullInt32{}
+		var statusErr *statusError
+		if errors.As(fetchErr, &statusErr) {
+			status = sql.NullInt32{Int32: int32(statusErr.StatusCode), Valid: true}
+		}
+		err := s.db.RecordFeedFailure(context.Background(), database.RecordFeedFailureParams{
+			ID:             feed.ID,
+			LastStatus:     status,
+			LastError:      sql.NullString{String: fetchErr.Error(), Valid: true},
+			ResponseTimeMs: responseTime,
+		})
+		if err != nil {
+			log.Printf("could not record failure of feed %s: %v", feed.Name, err)
+		}
+		return
+	}
+
+	// A 304 carries no items, keep the count from the last full fetch
+	itemCount := feed.ItemCount
+	if result.Feed != nil {
+		itemCount = sql.NullInt32{Int32: int32(len(result.Feed.Items)), Valid: true}
+	}
+	err := s.db.RecordFeedSuccess(context.Background(), database.RecordFeedSuccessParams{
+		ID:             feed.ID,
+		LastSuccessAt:  sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		LastStatus:     sql.NullInt32{Int32: int32(result.StatusCode), Valid: true},
+		ResponseTimeMs: responseTime,
+		ItemCount:      itemCount,
+	})
+	if err != nil {
+		log.Printf("could not record success of feed %s: %v", feed.Name, err)
+	}
+}
+
+// disableFailingFeed stops fetching a feed that is gone for good (HTTP 410)
+// or that has failed disableAfter times in a row.
+func disableFailingFeed(s *state, feed database.Feed, fetchErr error, disableAfter int) {
+	failures := int(feed.ConsecutiveFailures) + 1
+
+	reason := ""
+	var statusErr *statusError
+	if errors.As(fetchErr, &statusErr) && statusErr.StatusCode == http.StatusGone {
+		reason = "feed is gone (HTTP 410)"
+	} else if disableAfter > 0 && failures >= disableAfter {
+		reason = fmt.Sprintf("%d consecutive failures, last error: %v", failures, fetchErr)
+	}
+	if reason == "" {
+		return
+	}
+
+	err := s.db.DisableFeed(context.Background(), database.DisableFeedParams{
+		ID:             feed.ID,
+		DisabledReason: sql.NullString{String: reason, Valid: true},
+		UpdatedAt:      time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("could not disable feed %s: %v", feed.Name, err)
+		return
+	}
+	fmt.Printf("Disabled feed %s: %s\n", feed.Name, reason)
+}
+
+// moveFeed updates the stored URL of a feed that answered with a permanent
+// redirect. The old URL is kept as an alias so lookups by it still work.
+// If another feed already has the new URL, the two are the same feed and
+// this one is merged into it. It returns the feed the posts belong to now.
+func moveFeed(s *state, feed database.Feed, result *fetchResult) database.Feed {
+	hops := []string{feed.Url}
+	for _, r := range result.Redirects {
+		hops = append(hops, fmt.Sprintf("%d %s", r.StatusCode, r.To))
+	}
+
+	newURL := canonicalURL(result.PermanentURL)
+	if existing, err := getFeedByURL(s, newURL); err == nil && existing.ID != feed.ID {
+		err := withTx(s, func(tx *state) error {
+			return mergeFeed(tx, feed, existing, &canonicalizeStats{})
+		})
+		if err != nil {
+			log.Printf("could not merge feed %s into %s: %v", feed.Name, existing.Name, err)
+			return feed
+		}
+		fmt.Printf("Feed %s moved permanently: %s\n", feed.Name, strings.Join(hops, " -> "))
+		return existing
+	}
+
+	err := s.db.MoveFeed(context.Background(), database.MoveFeedParams{
+		OldUrl:    feed.Url,
+		OldUrlKey: feed.UrlKey,
+		ID:        feed.ID,
+		UpdatedAt: time.Now().UTC(),
+		NewUrl:    newURL,
+		NewUrlKey: urlnorm.Key(newURL),
+	})
+	if err != nil {
+		log.Printf("could not move feed %s to %s: %v", feed.Name, newURL, err)
+		return feed
+	}
+	fmt.Printf("Feed %s moved permanently: %s\n", feed.Name, strings.Join(hops, " -> "))
+	return feed
+}
+
+// storeCacheHeaders persists the ETag and Last-Modified validators of a
+// fetch so the next request for the feed can be conditional.
+func storeCacheHeaders(s *state, feed database.Feed, result *fetchResult) {
+	if result.ETag == feed.Etag.String && result.LastModified == feed.LastModified.String {
+		return
+	}
+	err := s.db.SetFeedCacheHeaders(context.Background(), database.SetFeedCacheHeadersParams{
+		ID:           feed.ID,
+		Etag:         sql.NullString{String: result.ETag, Valid: result.ETag != ""},
+		LastModified: sql.NullString{String: result.LastModified, Valid: result.LastModified != ""},
+	})
+	if err != nil {
+		log.Printf("could not store cache headers for feed %s: %v", feed.Name, err)
+	}
+}
+
+// hostLimiter bounds the number of concurrent requests to each host so a
+// large batch doesn't hammer a single server. It also queues the feeds of
+// a batch: a feed whose host is busy waits while feeds of other hosts are
+// handed out, so workers don't sit idle behind one slow server.
+type hostLimiter struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	limit   int
+	active  map[string]int
+	pending []database.Feed
+}
+
+func newHostLimiter(limit int, feeds []database.Feed) *hostLimiter {
+	l := &hostLimiter{
+		limit:   limit,
+		active:  make(map[string]int),
+		pending: feeds,
+	}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// next takes the first queued feed whose host has a free slot, together
+// with the slot. It waits while the hosts of all queued feeds are busy and
+// reports false once the queue is empty.
+func (l *hostLimiter) next() (database.Feed, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for len(l.pending) > 0 {
+		for i, feed := range l.pending {
+			host := feedHost(feed.Url)
+			if l.active[host] < l.limit {
+				l.active[host]++
+				l.pending = slices.Delete(l.pending, i, i+1)
+				return feed, true
+			}
+		}
+		l.cond.Wait()
+	}
+	return database.Feed{}, false
+}
+
+// acquire waits for a free slot on a host, for requests that aren't feed
+// fetches handed out by next.
+func (l *hostLimiter) acquire(host string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.active[host] >= l.limit {
+		l.cond.Wait()
+	}
+	l.active[host]++
+}
+
+func (l *hostLimiter) release(host string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active[host]--
+	if l.active[host] == 0 {
+		delete(l.active, host)
+	}
+	l.cond.Broadcast()
+}
+
+// feedHost returns the lowercased host of a feed URL, or the URL itself
+// if it can't be parsed.
+func feedHost(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil || u.Host == "" {
+		return feedURL
+	}
+	return strings.ToLower(u.Host)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/diverdib/gator/internal/database"
+	"github.com/diverdib/gator/internal/htmltext"
+)
+
+func handlerSearch(s *state, cmd command, user database.User) error {
+	usage := fmt.Errorf(`usage: %s <query> [--all] [--limit n] (use "quotes" for phrases and -word to exclude)`, cmd.name)
+
+	// The flag package would reject "-word" negations, so only the
+	// search's own options are picked out and everything else is the query
+	allFeeds := false
+	limit := 10
+	var terms []string
+	for i := 0; i < len(cmd.args); i++ {
+		arg := cmd.args[i]
+		switch {
+		case arg == "--all":
+			allFeeds = true
+		case arg == "--limit":
+			if i+1 >= len(cmd.args) {
+				return usage
+			}
+			i++
+			l, err := strconv.Atoi(cmd.args[i])
+			if err != nil || l < 1 {
+				return fmt.Errorf("invalid limit %q", cmd.args[i])
+			}
+			limit = l
+		case arg == "--":
+			terms = append(terms, cmd.args[i+1:]...)
+			i = len(cmd.args)
+		default:
+			terms = append(terms, arg)
+		}
+	}
+
+	query := strings.TrimSpace(strings.Join(terms, " "))
+	if query == "" {
+		return usage
+	}
+
+	results, err := s.db.SearchPosts(context.Background(), database.SearchPostsParams{
+		Query:    query,
+		AllFeeds: allFeeds,
+		UserID:   user.ID,
+		Limit:    int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("could not search posts: %w", err)
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No posts match %q\n", query)
+		return nil
+	}
+
+	fmt.Printf("Found %d posts matching %q:\n", len(results), query)
+	for _, post := range results {
+		fmt.Printf("%s from %s (%s)\n", post.PublishedAt.Time.Format("Mon Jan _2"), htmltext.Printable(post.Title), htmltext.Printable(post.FeedName))
+		fmt.Printf("--- %s ---\n", post.Url)
+		fmt.Printf("ID: %s\n", post.ID)
+		// Snippets are cut out of the description with its tags removed,
+		// rendering decodes the entities left and flattens it to one line
+		snippet := strings.Join(strings.Fields(htmltext.Render(post.Snippet)), " ")
+		if snippet != "" {
+			fmt.Printf("%s\n", snippet)
+		}
+		fmt.Println()
+	}
+	return nil
+}
@@ -1,11 +1,16 @@
 -- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, site_url, description, language, image_url, url_key)
 VALUES (
     $1, -- id
     $2, -- created_at
     $3, -- updated_at
     $4, -- name
     $5, -- url
-    $6  -- user_id
+    $6, -- user_id
+    $7, -- site_url
+    $8, -- description
+    $9, -- language
+    $10, -- image_url
+    $11 -- url_key
 )
 RETURNING *;
\ No newline at end of file
@@ -0,0 +1,76 @@
+-- name: ListFeedsOldestFirst :many
+SELECT * FROM feeds
+ORDER BY created_at, id;
+
+-- name: MoveFeedFollows :exec
+UPDATE feed_follows
+SET feed_id = sqlc.arg(to_feed_id)
+WHERE feed_id = sqlc.arg(from_feed_id)
+AND user_id NOT IN (SELECT user_id FROM feed_follows WHERE feed_id = sqlc.arg(to_feed_id));
+
+-- name: MergeFollowTags :exec
+UPDATE feed_follows AS kept
+SET tags = ARRAY(SELECT DISTINCT tag FROM unnest(kept.tags || dup.tags) AS tag ORDER BY tag),
+    updated_at = GREATEST(kept.updated_at, dup.updated_at)
+FROM feed_follows AS dup
+WHERE kept.feed_id = sqlc.arg(to_feed_id)
+AND dup.feed_id = sqlc.arg(from_feed_id)
+AND kept.user_id = dup.user_id;
+
+-- name: MoveFeedAliases :exec
+UPDATE feed_aliases
+SET feed_id = sqlc.arg(to_feed_id)
+WHERE feed_id = sqlc.arg(from_feed_id);
+
+-- name: AddFeedAlias :exec
+INSERT INTO feed_aliases (url, url_key, feed_id, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (url) DO NOTHING;
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE id = $1;
+
+-- name: SetFeedUrl :exec
+UPDATE feeds
+SET url = $2,
+    url_key = $3,
+    updated_at = $4
+WHERE id = $1;
+
+-- name: ListFeedAliases :many
+SELECT * FROM feed_aliases
+ORDER BY url;
+
+-- name: SetFeedAliasKey :exec
+UPDATE feed_aliases
+SET url_key = $2
+WHERE url = $1;
+
+-- name: ListFeedPostUrls :many
+SELECT id, title, url, dedupe_key FROM posts
+WHERE feed_id = $1
+ORDER BY created_at, id;
+
+-- name: MovePost :exec
+UPDATE posts
+SET feed_id = $2,
+    url = $3
+WHERE id = $1;
+
+-- name: MergePostStates :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read, read_at)
+SELECT user_id, sqlc.arg(to_post_id)::uuid, created_at, updated_at, read, read_at
+FROM post_states
+WHERE post_id = sqlc.arg(from_post_id)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read = post_states.read OR EXCLUDED.read,
+    read_at = COALESCE(post_states.read_at, EXCLUDED.read_at),
+    updated_at = GREATEST(post_states.updated_at, EXCLUDED.updated_at);
+
+-- name: MovePostRevisions :exec
+UPDATE post_revisions
+SET post_id = sqlc.arg(to_post_id)
+WHERE post_id = sqlc.arg(from_post_id);
+
+-- name: DeletePost :exec
+DELETE FROM posts WHERE id = $1;
@@ -0,0 +1,14 @@
+-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET last_fetched_at = $1,
+    updated_at = $1,
+    next_fetch_at = $2
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE enabled
+    AND (next_fetch_at IS NULL OR next_fetch_at <= $1)
+    ORDER BY next_fetch_at ASC NULLS FIRST, last_fetched_at ASC NULLS FIRST
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
@@ -1,6 +1,8 @@
 -- name: DeleteFeedFollow :execresult
 DELETE FROM feed_follows
 WHERE feed_follows.user_id = $1
-AND feed_follows.feed_id = (
-    SELECT id FROM feeds WHERE url = $2
+AND feed_follows.feed_id IN (
+    SELECT id FROM feeds WHERE url = $2 OR url_key = $3
+    UNION
+    SELECT feed_id FROM feed_aliases WHERE feed_aliases.url = $2 OR feed_aliases.url_key = $3
 );
@@ -0,0 +1,5 @@
+-- name: SetFeedCacheHeaders :exec
+UPDATE feeds
+SET etag = $2,
+    last_modified = $3
+WHERE id = $1;
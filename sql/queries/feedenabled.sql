@@ -0,0 +1,15 @@
+-- name: DisableFeed :exec
+UPDATE feeds
+SET enabled = FALSE,
+    disabled_reason = $2,
+    updated_at = $3
+WHERE id = $1;
+
+-- name: EnableFeed :exec
+UPDATE feeds
+SET enabled = TRUE,
+    disabled_reason = NULL,
+    consecutive_failures = 0,
+    next_fetch_at = NULL,
+    updated_at = $2
+WHERE id = $1;
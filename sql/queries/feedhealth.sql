@@ -0,0 +1,15 @@
+-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET last_success_at = $2,
+    last_status = $3,
+    last_error = NULL,
+    response_time_ms = $4,
+    item_count = $5
+WHERE id = $1;
+
+-- name: RecordFeedFailure :exec
+UPDATE feeds
+SET last_status = $2,
+    last_error = $3,
+    response_time_ms = $4
+WHERE id = $1;
@@ -0,0 +1,11 @@
+-- name: SetFeedMetadata :exec
+UPDATE feeds
+SET updated_at = $2,
+    site_url = $3,
+    description = $4,
+    language = $5,
+    image_url = $6,
+    ttl_minutes = $7,
+    skip_hours = $8,
+    skip_days = $9
+WHERE id = $1;
@@ -1,2 +1,12 @@
 -- name: GetFeedByUrl :one
-SELECT * FROM feeds WHERE url = $1;
\ No newline at end of file
+SELECT * FROM feeds
+WHERE url = $1
+OR url_key = $2
+OR id = (
+    SELECT feed_id FROM feed_aliases
+    WHERE feed_aliases.url = $1 OR feed_aliases.url_key = $2
+    ORDER BY feed_aliases.url = $1 DESC
+    LIMIT 1
+)
+ORDER BY url = $1 DESC, url_key = $2 DESC
+LIMIT 1;
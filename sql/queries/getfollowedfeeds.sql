@@ -0,0 +1,6 @@
+-- name: GetFollowedFeeds :many
+SELECT feeds.*
+FROM feeds
+JOIN feed_follows ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.name;
@@ -2,8 +2,17 @@
 SELECT
     feed_follows.*,
     feeds.name AS feed_name,
-    users.name AS user_name
+    users.name AS user_name,
+    (
+        SELECT COUNT(*)
+        FROM posts
+        LEFT JOIN post_states ON post_states.post_id = posts.id
+            AND post_states.user_id = feed_follows.user_id
+        WHERE posts.feed_id = feed_follows.feed_id
+        AND NOT COALESCE(post_states.read, FALSE)
+    ) AS unread_count
 FROM feed_follows
 INNER JOIN feeds on feed_follows.feed_id = feeds.id
 INNER JOIN users on feed_follows.user_id = users.id
-WHERE feed_follows.user_id = $1;
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.name;
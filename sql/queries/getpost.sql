@@ -0,0 +1,14 @@
+-- name: GetFollowedPost :one
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description,
+    posts.published_at, posts.feed_id, posts.content, posts.author, posts.categories, posts.guid,
+    posts.comments_url, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length,
+    posts.dedupe_key, posts.edited_at, posts.published_at_estimated, posts.original_url, posts.url_resolved
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1 AND posts.id = $2;
+
+-- name: GetPostByDedupeKey :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id,
+    content, author, categories, guid, comments_url, enclosure_url, enclosure_type, enclosure_length,
+    dedupe_key, edited_at, published_at_estimated, original_url, url_resolved
+FROM posts WHERE feed_id = $1 AND dedupe_key = $2;
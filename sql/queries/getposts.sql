@@ -1,7 +1,39 @@
--- name: GetPostsForUser :many
-SELECT posts.* 
+-- name: BrowsePosts :many
+SELECT
+    posts.id,
+    posts.created_at,
+    posts.updated_at,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.feed_id,
+    posts.content,
+    posts.author,
+    posts.categories,
+    posts.edited_at,
+    feeds.name AS feed_name,
+    COALESCE(post_states.read, FALSE) AS read,
+    (CASE WHEN sqlc.arg(sort_by_fetched)::bool THEN posts.created_at
+        ELSE COALESCE(posts.published_at, posts.created_at) END)::timestamp AS sort_time
 FROM posts
 JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
-WHERE feed_follows.user_id = $1
-ORDER BY posts.published_at DESC
-LIMIT $2;
\ No newline at end of file
+JOIN feeds ON posts.feed_id = feeds.id
+LEFT JOIN post_states ON post_states.post_id = posts.id
+    AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (NOT sqlc.arg(unread_only)::bool OR NOT COALESCE(post_states.read, FALSE))
+AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+AND (sqlc.narg(since)::timestamp IS NULL OR (CASE WHEN sqlc.arg(sort_by_fetched)::bool THEN posts.created_at
+    ELSE COALESCE(posts.published_at, posts.created_at) END) >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR (CASE WHEN sqlc.arg(sort_by_fetched)::bool THEN posts.created_at
+    ELSE COALESCE(posts.published_at, posts.created_at) END) < sqlc.narg(until))
+AND (sqlc.narg(author)::text IS NULL OR posts.author ILIKE '%' || sqlc.narg(author) || '%')
+AND (sqlc.narg(category)::text IS NULL OR EXISTS (
+    SELECT 1 FROM unnest(posts.categories) AS category WHERE lower(category) = lower(sqlc.narg(category))
+))
+AND (sqlc.narg(tag)::text IS NULL OR lower(sqlc.narg(tag)) = ANY(feed_follows.tags))
+AND (sqlc.narg(after_time)::timestamp IS NULL OR ((CASE WHEN sqlc.arg(sort_by_fetched)::bool THEN posts.created_at
+    ELSE COALESCE(posts.published_at, posts.created_at) END), posts.id) < (sqlc.narg(after_time), sqlc.narg(after_id)::uuid))
+ORDER BY sort_time DESC, posts.id DESC
+LIMIT sqlc.arg('limit');
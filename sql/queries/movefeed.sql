@@ -0,0 +1,11 @@
+-- name: MoveFeed :exec
+WITH alias AS (
+    INSERT INTO feed_aliases (url, url_key, feed_id, created_at)
+    VALUES (sqlc.arg(old_url), sqlc.arg(old_url_key), sqlc.arg(id), sqlc.arg(updated_at))
+    ON CONFLICT (url) DO NOTHING
+)
+UPDATE feeds
+SET url = sqlc.arg(new_url),
+    url_key = sqlc.arg(new_url_key),
+    updated_at = sqlc.arg(updated_at)
+WHERE id = sqlc.arg(id);
@@ -0,0 +1,25 @@
+-- name: MarkPostRead :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read, read_at)
+SELECT feed_follows.user_id, posts.id, sqlc.arg(read_at), sqlc.arg(read_at), TRUE, sqlc.arg(read_at)
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id) AND posts.id = sqlc.arg(post_id)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read = TRUE,
+    read_at = EXCLUDED.read_at,
+    updated_at = EXCLUDED.updated_at
+WHERE NOT post_states.read;
+
+-- name: MarkPostsRead :execrows
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read, read_at)
+SELECT feed_follows.user_id, posts.id, sqlc.arg(read_at), sqlc.arg(read_at), TRUE, sqlc.arg(read_at)
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+AND (sqlc.narg(before)::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) < sqlc.narg(before))
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read = TRUE,
+    read_at = EXCLUDED.read_at,
+    updated_at = EXCLUDED.updated_at
+WHERE NOT post_states.read;
@@ -0,0 +1,20 @@
+-- name: SavePost :one
+INSERT INTO saved_posts (user_id, post_id, created_at, updated_at, title, url, description, published_at, feed_name, note)
+SELECT sqlc.arg(user_id), posts.id, sqlc.arg(saved_at), sqlc.arg(saved_at), posts.title, posts.url, posts.description, posts.published_at, feeds.name, sqlc.narg(note)
+FROM posts
+JOIN feeds ON posts.feed_id = feeds.id
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id) AND posts.id = sqlc.arg(post_id)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET note = COALESCE(EXCLUDED.note, saved_posts.note),
+    updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+-- name: UnsavePost :execrows
+DELETE FROM saved_posts
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetSavedPosts :many
+SELECT * FROM saved_posts
+WHERE user_id = $1
+ORDER BY created_at DESC;
@@ -0,0 +1,5 @@
+-- name: ScheduleFeedFetch :exec
+UPDATE feeds
+SET next_fetch_at = $2,
+    consecutive_failures = $3
+WHERE id = $1;
@@ -0,0 +1,24 @@
+-- name: SearchPosts :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    feeds.name AS feed_name,
+    ts_rank(posts.search_vector, query) AS rank,
+    ts_headline(
+        'english',
+        regexp_replace(coalesce(posts.description, ''), '<[^>]*>', ' ', 'g'),
+        query,
+        'MaxWords=30, MinWords=10, StartSel=*, StopSel=*'
+    ) AS snippet
+FROM posts
+JOIN feeds ON posts.feed_id = feeds.id,
+    websearch_to_tsquery('english', sqlc.arg(query)) query
+WHERE posts.search_vector @@ query
+AND (
+    sqlc.arg(all_feeds)::bool
+    OR posts.feed_id IN (SELECT feed_id FROM feed_follows WHERE user_id = sqlc.arg(user_id))
+)
+ORDER BY rank DESC, posts.published_at DESC NULLS LAST
+LIMIT sqlc.arg('limit');
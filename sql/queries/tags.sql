@@ -0,0 +1,11 @@
+-- name: SetFollowTags :execrows
+UPDATE feed_follows
+SET tags = $3,
+    updated_at = $4
+WHERE user_id = $1 AND feed_id = $2;
+
+-- name: AddFollowTags :exec
+UPDATE feed_follows
+SET tags = ARRAY(SELECT DISTINCT tag FROM unnest(tags || sqlc.arg(tags)::text[]) AS tag ORDER BY tag),
+    updated_at = sqlc.arg(updated_at)
+WHERE user_id = sqlc.arg(user_id) AND feed_id = sqlc.arg(feed_id);
@@ -0,0 +1,90 @@
+-- name: UpsertPost :one
+WITH previous AS (
+    SELECT id, title, description, content FROM posts
+    WHERE feed_id = sqlc.arg(feed_id) AND dedupe_key = sqlc.arg(dedupe_key)
+),
+upserted AS (
+    INSERT INTO posts (
+        id, created_at, updated_at, title, url, description, published_at, feed_id,
+        content, author, categories, guid, comments_url, enclosure_url, enclosure_type, enclosure_length,
+        dedupe_key, published_at_estimated, original_url, url_resolved
+    )
+    VALUES (
+        sqlc.arg(id),
+        sqlc.arg(created_at),
+        sqlc.arg(updated_at),
+        sqlc.arg(title),
+        sqlc.arg(url),
+        sqlc.arg(description),
+        sqlc.arg(published_at),
+        sqlc.arg(feed_id),
+        sqlc.arg(content),
+        sqlc.arg(author),
+        sqlc.arg(categories),
+        sqlc.arg(guid),
+        sqlc.arg(comments_url),
+        sqlc.arg(enclosure_url),
+        sqlc.arg(enclosure_type),
+        sqlc.arg(enclosure_length),
+        sqlc.arg(dedupe_key),
+        sqlc.arg(published_at_estimated),
+        sqlc.arg(original_url),
+        sqlc.arg(url_resolved)
+    )
+    ON CONFLICT (feed_id, dedupe_key) DO UPDATE SET
+        updated_at = EXCLUDED.updated_at,
+        edited_at = CASE
+            WHEN (posts.title, posts.description, posts.content)
+                IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.description, EXCLUDED.content)
+            THEN EXCLUDED.updated_at
+            ELSE posts.edited_at
+        END,
+        title = EXCLUDED.title,
+        url = CASE
+            WHEN posts.url_resolved AND NOT EXCLUDED.url_resolved
+                AND posts.original_url IS NOT DISTINCT FROM EXCLUDED.original_url
+            THEN posts.url
+            ELSE EXCLUDED.url
+        END,
+        url_resolved = CASE
+            WHEN posts.url_resolved AND NOT EXCLUDED.url_resolved
+                AND posts.original_url IS NOT DISTINCT FROM EXCLUDED.original_url
+            THEN TRUE
+            ELSE EXCLUDED.url_resolved
+        END,
+        original_url = EXCLUDED.original_url,
+        description = EXCLUDED.description,
+        published_at = CASE WHEN EXCLUDED.published_at_estimated THEN posts.published_at ELSE EXCLUDED.published_at END,
+        published_at_estimated = posts.published_at_estimated AND EXCLUDED.published_at_estimated,
+        content = EXCLUDED.content,
+        author = EXCLUDED.author,
+        categories = EXCLUDED.categories,
+        comments_url = EXCLUDED.comments_url,
+        enclosure_url = EXCLUDED.enclosure_url,
+        enclosure_type = EXCLUDED.enclosure_type,
+        enclosure_length = EXCLUDED.enclosure_length
+    WHERE (posts.title, posts.description, posts.content, posts.author,
+        posts.categories, posts.comments_url, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length)
+        IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.description, EXCLUDED.content, EXCLUDED.author,
+        EXCLUDED.categories, EXCLUDED.comments_url, EXCLUDED.enclosure_url, EXCLUDED.enclosure_type, EXCLUDED.enclosure_length)
+    OR (NOT EXCLUDED.published_at_estimated AND posts.published_at IS DISTINCT FROM EXCLUDED.published_at)
+    OR (posts.url IS DISTINCT FROM EXCLUDED.url
+        AND (EXCLUDED.url_resolved OR NOT posts.url_resolved
+            OR posts.original_url IS DISTINCT FROM EXCLUDED.original_url))
+    RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id,
+        content, author, categories, guid, comments_url, enclosure_url, enclosure_type, enclosure_length,
+        dedupe_key, edited_at, published_at_estimated, original_url, url_resolved
+),
+revision AS (
+    INSERT INTO post_revisions (id, post_id, created_at, title, description, content)
+    SELECT sqlc.arg(revision_id)::uuid, previous.id, upserted.updated_at, previous.title, previous.description, previous.content
+    FROM previous
+    JOIN upserted ON upserted.id = previous.id
+    WHERE sqlc.arg(keep_revision)::bool
+    AND (previous.title, previous.description, previous.content)
+        IS DISTINCT FROM (upserted.title, upserted.description, upserted.content)
+)
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id,
+    content, author, categories, guid, comments_url, enclosure_url, enclosure_type, enclosure_length,
+    dedupe_key, edited_at, published_at_estimated, original_url, url_resolved
+FROM upserted;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN last_success_at TIMESTAMP,
+ADD COLUMN last_status INTEGER,
+ADD COLUMN last_error TEXT,
+ADD COLUMN response_time_ms INTEGER,
+ADD COLUMN item_count INTEGER;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN last_success_at,
+DROP COLUMN last_status,
+DROP COLUMN last_error,
+DROP COLUMN response_time_ms,
+DROP COLUMN item_count;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN enabled BOOLEAN NOT NULL DEFAULT TRUE,
+ADD COLUMN disabled_reason TEXT;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN enabled,
+DROP COLUMN disabled_reason;
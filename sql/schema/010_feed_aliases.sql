@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE feed_aliases (
+    url TEXT PRIMARY KEY,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE feed_aliases;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- post_id has no foreign key on purpose: saved posts keep a copy of the
+-- post so they survive when old posts are pruned.
+CREATE TABLE saved_posts (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL,
+    description TEXT,
+    published_at TIMESTAMP,
+    feed_name TEXT NOT NULL,
+    note TEXT,
+    PRIMARY KEY (user_id, post_id)
+);
+
+-- +goose Down
+DROP TABLE saved_posts;
@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN content TEXT,
+ADD COLUMN author TEXT,
+ADD COLUMN categories TEXT[] NOT NULL DEFAULT '{}',
+ADD COLUMN guid TEXT,
+ADD COLUMN comments_url TEXT,
+ADD COLUMN enclosure_url TEXT,
+ADD COLUMN enclosure_type TEXT,
+ADD COLUMN enclosure_length BIGINT;
+
+-- +goose Down
+ALTER TABLE posts
+DROP COLUMN content,
+DROP COLUMN author,
+DROP COLUMN categories,
+DROP COLUMN guid,
+DROP COLUMN comments_url,
+DROP COLUMN enclosure_url,
+DROP COLUMN enclosure_type,
+DROP COLUMN enclosure_length;
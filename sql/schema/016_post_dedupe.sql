@@ -0,0 +1,19 @@
+-- +goose Up
+-- Posts are identified by their GUID within a feed, or by a hash of link
+-- and title for items without one. The same URL may appear in many feeds.
+ALTER TABLE posts
+ADD COLUMN dedupe_key TEXT;
+
+UPDATE posts
+SET dedupe_key = COALESCE(guid, encode(sha256(convert_to(url || E'\n' || title, 'UTF8')), 'hex'));
+
+ALTER TABLE posts
+ALTER COLUMN dedupe_key SET NOT NULL,
+DROP CONSTRAINT posts_url_key,
+ADD CONSTRAINT posts_feed_id_dedupe_key_key UNIQUE (feed_id, dedupe_key);
+
+-- +goose Down
+ALTER TABLE posts
+DROP CONSTRAINT posts_feed_id_dedupe_key_key,
+ADD CONSTRAINT posts_url_key UNIQUE (url),
+DROP COLUMN dedupe_key;
@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN edited_at TIMESTAMP;
+
+CREATE TABLE post_revisions (
+    id UUID PRIMARY KEY,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT,
+    content TEXT
+);
+
+-- +goose Down
+DROP TABLE post_revisions;
+
+ALTER TABLE posts
+DROP COLUMN edited_at;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Posts without a usable date are dated by when they were first fetched,
+-- so they sort with their neighbours instead of ahead of every post.
+ALTER TABLE posts
+ADD COLUMN published_at_estimated BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE posts
+SET published_at = created_at,
+    published_at_estimated = TRUE
+WHERE published_at IS NULL;
+
+-- +goose Down
+UPDATE posts
+SET published_at = NULL
+WHERE published_at_estimated;
+
+ALTER TABLE posts
+DROP COLUMN published_at_estimated;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN description TEXT,
+ADD COLUMN language TEXT,
+ADD COLUMN image_url TEXT;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN description,
+DROP COLUMN language,
+DROP COLUMN image_url;
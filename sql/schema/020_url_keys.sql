@@ -0,0 +1,31 @@
+-- +goose Up
+-- url_key is the canonical identity of a URL as computed by the urlnorm
+-- package. SQL can't compute it, so existing rows start out keyed by their
+-- raw URL until "gator canonicalize" rewrites them and merges duplicates.
+ALTER TABLE feeds
+ADD COLUMN url_key TEXT;
+
+UPDATE feeds SET url_key = url;
+
+ALTER TABLE feeds
+ALTER COLUMN url_key SET NOT NULL,
+ADD CONSTRAINT feeds_url_key_key UNIQUE (url_key);
+
+ALTER TABLE feed_aliases
+ADD COLUMN url_key TEXT;
+
+UPDATE feed_aliases SET url_key = url;
+
+ALTER TABLE feed_aliases
+ALTER COLUMN url_key SET NOT NULL;
+
+CREATE INDEX feed_aliases_url_key_idx ON feed_aliases (url_key);
+
+-- +goose Down
+DROP INDEX feed_aliases_url_key_idx;
+
+ALTER TABLE feed_aliases
+DROP COLUMN url_key;
+
+ALTER TABLE feeds
+DROP COLUMN url_key;
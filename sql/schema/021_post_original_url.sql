@@ -0,0 +1,12 @@
+-- +goose Up
+-- url is where the post points after unwrapping redirect links and
+-- stripping tracking parameters, original_url is the link as the feed had
+-- it. Existing posts were stored with their links as is.
+ALTER TABLE posts
+ADD COLUMN original_url TEXT;
+
+UPDATE posts SET original_url = url;
+
+-- +goose Down
+ALTER TABLE posts
+DROP COLUMN original_url;
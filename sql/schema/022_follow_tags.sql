@@ -0,0 +1,9 @@
+-- +goose Up
+-- Tags are per follow, so every user organizes the feeds they follow
+-- their own way. They are stored lowercased.
+ALTER TABLE feed_follows
+ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE feed_follows
+DROP COLUMN tags;
@@ -0,0 +1,50 @@
+-- +goose Up
+-- Posts without a GUID are keyed on their link as the feed has it, or on
+-- their title when they have no link, instead of on link and title. Posts
+-- that now share a key are the same item with an edited title: the oldest
+-- one is kept and gets the read states and revisions of the others.
+CREATE TEMPORARY TABLE post_keys ON COMMIT DROP AS
+SELECT
+    id,
+    dedupe_key,
+    first_value(id) OVER (PARTITION BY feed_id, dedupe_key ORDER BY created_at, id) AS kept_id
+FROM (
+    SELECT id, feed_id, created_at,
+        COALESCE(guid, encode(sha256(convert_to(
+            COALESCE(NULLIF(original_url, ''), NULLIF(url, ''), title), 'UTF8'
+        )), 'hex')) AS dedupe_key
+    FROM posts
+) AS keyed;
+
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read, read_at)
+SELECT post_states.user_id, post_keys.kept_id, min(post_states.created_at), max(post_states.updated_at),
+    bool_or(post_states.read), min(post_states.read_at)
+FROM post_states
+JOIN post_keys ON post_states.post_id = post_keys.id
+WHERE post_keys.id <> post_keys.kept_id
+GROUP BY post_states.user_id, post_keys.kept_id
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read = post_states.read OR EXCLUDED.read,
+    read_at = COALESCE(post_states.read_at, EXCLUDED.read_at),
+    updated_at = GREATEST(post_states.updated_at, EXCLUDED.updated_at);
+
+UPDATE post_revisions
+SET post_id = post_keys.kept_id
+FROM post_keys
+WHERE post_revisions.post_id = post_keys.id
+AND post_keys.id <> post_keys.kept_id;
+
+DELETE FROM posts
+USING post_keys
+WHERE posts.id = post_keys.id
+AND post_keys.id <> post_keys.kept_id;
+
+UPDATE posts
+SET dedupe_key = post_keys.dedupe_key
+FROM post_keys
+WHERE posts.id = post_keys.id
+AND posts.dedupe_key <> post_keys.dedupe_key;
+
+-- +goose Down
+UPDATE posts
+SET dedupe_key = COALESCE(guid, encode(sha256(convert_to(url || E'\n' || title, 'UTF8')), 'hex'));
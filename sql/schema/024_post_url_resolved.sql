@@ -0,0 +1,11 @@
+-- +goose Up
+-- url_resolved is set once a post link went through every redirector it
+-- wraps. Links that couldn't be followed stay unresolved and are tried
+-- again on the next fetch. Existing posts can't tell a resolved link from
+-- a failed one, they start unresolved so wrapped links are followed again.
+ALTER TABLE posts
+ADD COLUMN url_resolved BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE posts
+DROP COLUMN url_resolved;
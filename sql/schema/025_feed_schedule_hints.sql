@@ -0,0 +1,14 @@
+-- +goose Up
+-- The <ttl>, <skipHours> and <skipDays> of a feed, in minutes, GMT hours
+-- and weekdays with Sunday as 0. A 304 carries no feed, so these are what
+-- the next fetch is scheduled by when the feed hasn't changed.
+ALTER TABLE feeds
+ADD COLUMN ttl_minutes INTEGER,
+ADD COLUMN skip_hours INTEGER[] NOT NULL DEFAULT '{}',
+ADD COLUMN skip_days INTEGER[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN ttl_minutes,
+DROP COLUMN skip_hours,
+DROP COLUMN skip_days;
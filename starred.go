@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diverdib/gator/internal/database"
+	"github.com/diverdib/gator/internal/htmltext"
+	"github.com/google/uuid"
+)
+
+func handlerStar(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("usage: %s <post_id> [note]", cmd.name)
+	}
+
+	postID, err := uuid.Parse(cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("invalid post ID %s: %w", cmd.args[0], err)
+	}
+
+	// Starring again without a note keeps the note that is already there
+	note := strings.TrimSpace(strings.Join(cmd.args[1:], " "))
+
+	saved, err := s.db.SavePost(context.Background(), database.SavePostParams{
+		UserID:  user.ID,
+		SavedAt: time.Now().UTC(),
+		Note:    sql.NullString{String: note, Valid: note != ""},
+		PostID:  postID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("post %s isn't in a feed you follow", postID)
+	}
+	if err != nil {
+		return fmt.Errorf("could not star post: %w", err)
+	}
+
+	fmt.Printf("Starred %s\n", htmltext.Printable(saved.Title))
+	if saved.Note.Valid {
+		fmt.Printf("Note: %s\n", saved.Note.String)
+	}
+	return nil
+}
+
+func handlerUnstar(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 1 {
+		return fmt.Errorf("usage: %s <post_id>", cmd.name)
+	}
+
+	postID, err := uuid.Parse(cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("invalid post ID %s: %w", cmd.args[0], err)
+	}
+
+	rows, err := s.db.UnsavePost(context.Background(), database.UnsavePostParams{
+		UserID: user.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		return fmt.Errorf("could not unstar post: %w", err)
+	}
+
+	if rows == 0 {
+		fmt.Printf("Post %s wasn't starred\n", postID)
+		return nil
+	}
+
+	fmt.Printf("Unstarred post %s\n", postID)
+	return nil
+}
+
+func handlerStarred(s *state, cmd command, user database.User) error {
+	saved, err := s.db.GetSavedPosts(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("could not get starred posts: %w", err)
+	}
+
+	if len(saved) == 0 {
+		fmt.Println("You haven't starred any posts yet.")
+		return nil
+	}
+
+	fmt.Printf("Starred posts of %s:\n", user.Name)
+	for _, post := range saved {
+		fmt.Printf("%s from %s (%s)\n", post.PublishedAt.Time.Format("Mon Jan _2"), htmltext.Printable(post.Title), htmltext.Printable(post.FeedName))
+		fmt.Printf("--- %s ---\n", post.Url)
+		fmt.Printf("ID: %s\n", post.PostID)
+		if post.Note.Valid {
+			fmt.Printf("Note: %s\n", post.Note.String)
+		}
+		fmt.Println()
+	}
+	return nil
+}
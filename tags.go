@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/diverdib/gator/internal/database"
+)
+
+// handlerTag replaces the tags of one of the feeds the user follows.
+// Without tags the feed is untagged.
+func handlerTag(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("usage: %s <url|name> [tag...]", cmd.name)
+	}
+
+	feed, err := resolveFollowedFeed(s, user, cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	tags := cleanTags(cmd.args[1:])
+	rows, err := s.db.SetFollowTags(context.Background(), database.SetFollowTagsParams{
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+		Tags:      tags,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("could not tag feed: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("you aren't following %s", feed.Name)
+	}
+
+	if len(tags) == 0 {
+		fmt.Printf("Removed the tags of %s\n", feed.Name)
+		return nil
+	}
+	fmt.Printf("Tagged %s: %s\n", feed.Name, strings.Join(tags, ", "))
+	return nil
+}
+
+// cleanTags lowercases and sorts tags and drops empty and repeated ones.
+// The result is never nil, a nil slice would be stored as NULL.
+func cleanTags(tags []string) []string {
+	clean := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || slices.Contains(clean, tag) {
+			continue
+		}
+		clean = append(clean, tag)
+	}
+	slices.Sort(clean)
+	return clean
+}
+
+// followsByTag groups follows by tag, in tag order with untagged follows
+// under "" at the end. Follows with several tags are in each group.
+func followsByTag(follows []database.GetFeedFollowsForUserRow) ([]string, map[string][]database.GetFeedFollowsForUserRow) {
+	var tags []string
+	groups := make(map[string][]database.GetFeedFollowsForUserRow)
+	for _, f := range follows {
+		followTags := f.Tags
+		if len(followTags) == 0 {
+			followTags = []string{""}
+		}
+		for _, tag := range followTags {
+			if _, ok := groups[tag]; !ok {
+				tags = append(tags, tag)
+			}
+			groups[tag] = append(groups[tag], f)
+		}
+	}
+
+	slices.SortFunc(tags, func(a, b string) int {
+		// Untagged goes last
+		switch {
+		case a == b:
+			return 0
+		case a == "":
+			return 1
+		case b == "":
+			return -1
+		}
+		return strings.Compare(a, b)
+	})
+	return tags, groups
+}
@@ -0,0 +1,691 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/diverdib/gator/internal/database"
+	"github.com/diverdib/gator/internal/htmltext"
+	"github.com/google/uuid"
+	"golang.org/x/term"
+)
+
+// tuiPostLimit is how many posts are loaded for the selected feed
+const tuiPostLimit = 200
+
+// Minimum widths of the panes. Terminals too narrow for all three only
+// show the focused pane.
+const (
+	minFeedWidth   = 16
+	minPostWidth   = 24
+	minReaderWidth = 10
+)
+
+// Panes of the terminal reader, in the order Tab cycles through them
+const (
+	paneFeeds = iota
+	panePosts
+	paneReader
+)
+
+// Key names produced by readKey
+const (
+	keyUp       = "up"
+	keyDown     = "down"
+	keyLeft     = "left"
+	keyRight    = "right"
+	keyPageUp   = "pgup"
+	keyPageDown = "pgdn"
+	keyEnter    = "enter"
+	keyTab      = "tab"
+)
+
+// tuiFeed is an entry in the feed pane. The first entry has no ID and
+// shows the posts of all followed feeds.
+type tuiFeed struct {
+	ID     uuid.NullUUID
+	Name   string
+	Unread int64
+}
+
+type tui struct {
+	s    *state
+	user database.User
+	in   *bufio.Reader
+
+	feeds []tuiFeed
+	posts []database.BrowsePostsRow
+	// seen marks posts opened in this session
+	seen map[uuid.UUID]bool
+
+	focus   int
+	feedIdx int
+	postIdx int
+	scroll  int
+	status  string
+}
+
+func handlerTUI(s *state, cmd command, user database.User) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("%s needs an interactive terminal", cmd.name)
+	}
+
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("could not switch terminal to raw mode: %w", err)
+	}
+	defer term.Restore(fd, oldState)
+
+	// Use the alternate screen so the shell comes back untouched on exit
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	t := &tui{
+		s:    s,
+		user: user,
+		in:   bufio.NewReader(os.Stdin),
+		seen: make(map[uuid.UUID]bool),
+	}
+	if err := t.loadFeeds(); err != nil {
+		return err
+	}
+	if err := t.loadPosts(); err != nil {
+		return err
+	}
+	return t.run()
+}
+
+func (t *tui) run() error {
+	for {
+		t.draw()
+
+		key, err := t.readKey()
+		if err != nil {
+			return err
+		}
+		t.status = ""
+
+		switch key {
+		case "q", "\x03":
+			return nil
+		case keyTab:
+			t.focus = (t.focus + 1) % 3
+		case keyLeft, "h":
+			t.focus = max(t.focus-1, paneFeeds)
+		case keyRight, "l":
+			t.focus = min(t.focus+1, paneReader)
+		case keyUp, "k":
+			t.move(-1)
+		case keyDown, "j":
+			t.move(1)
+		case keyPageUp:
+			t.move(-t.pageSize())
+		case keyPageDown:
+			t.move(t.pageSize())
+		case keyEnter:
+			t.enter()
+		case "o":
+			t.openInBrowser()
+		case "m":
+			t.markRead()
+		}
+	}
+}
+
+// move changes the selection of the focused pane, or scrolls the reader.
+func (t *tui) move(delta int) {
+	switch t.focus {
+	case paneFeeds:
+		idx := clamp(t.feedIdx+delta, 0, len(t.feeds)-1)
+		if idx != t.feedIdx {
+			t.feedIdx = idx
+			if err := t.loadPosts(); err != nil {
+				t.status = err.Error()
+			}
+		}
+	case panePosts:
+		idx := clamp(t.postIdx+delta, 0, len(t.posts)-1)
+		if idx != t.postIdx {
+			t.postIdx = idx
+			t.scroll = 0
+		}
+	case paneReader:
+		t.scroll = max(t.scroll+delta, 0)
+	}
+}
+
+// enter moves the focus one pane to the right; opening a post in the
+// reader marks it as seen for the rest of the session.
+func (t *tui) enter() {
+	switch t.focus {
+	case paneFeeds:
+		t.focus = panePosts
+	case panePosts:
+		if post, ok := t.selectedPost(); ok {
+			t.seen[post.ID] = true
+			t.scroll = 0
+			t.focus = paneReader
+		}
+	}
+}
+
+func (t *tui) openInBrowser() {
+	post, ok := t.selectedPost()
+	if !ok {
+		return
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", post.Url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", post.Url)
+	default:
+		cmd = exec.Command("xdg-open", post.Url)
+	}
+	if err := cmd.Start(); err != nil {
+		t.status = fmt.Sprintf("could not open browser: %v", err)
+		return
+	}
+	t.seen[post.ID] = true
+	t.status = "Opened " + post.Url
+}
+
+func (t *tui) markRead() {
+	post, ok := t.selectedPost()
+	if !ok || post.Read {
+		return
+	}
+
+	err := t.s.db.MarkPostRead(context.Background(), database.MarkPostReadParams{
+		ReadAt: time.Now().UTC(),
+		UserID: t.user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		t.status = fmt.Sprintf("could not mark post as read: %v", err)
+		return
+	}
+	t.posts[t.postIdx].Read = true
+	// The post counts towards both its own feed and "All feeds"
+	for i, f := range t.feeds {
+		if (i == 0 || f.ID.UUID == post.FeedID) && f.Unread > 0 {
+			t.feeds[i].Unread--
+		}
+	}
+	t.status = "Marked as read"
+}
+
+func (t *tui) selectedPost() (database.BrowsePostsRow, bool) {
+	if t.postIdx < 0 || t.postIdx >= len(t.posts) {
+		return database.BrowsePostsRow{}, false
+	}
+	return t.posts[t.postIdx], true
+}
+
+func (t *tui) loadFeeds() error {
+	follows, err := t.s.db.GetFeedFollowsForUser(context.Background(), t.user.ID)
+	if err != nil {
+		return fmt.Errorf("could not get feeds for user %s: %w", t.user.Name, err)
+	}
+
+	all := tuiFeed{Name: "All feeds"}
+	t.feeds = []tuiFeed{all}
+	for _, f := range follows {
+		t.feeds[0].Unread += f.UnreadCount
+		t.feeds = append(t.feeds, tuiFeed{
+			ID:     uuid.NullUUID{UUID: f.FeedID, Valid: true},
+			Name:   f.FeedName,
+			Unread: f.UnreadCount,
+		})
+	}
+	return nil
+}
+
+func (t *tui) loadPosts() error {
+	posts, err := t.s.db.BrowsePosts(context.Background(), database.BrowsePostsParams{
+		UserID: t.user.ID,
+		FeedID: t.feeds[t.feedIdx].ID,
+		Limit:  tuiPostLimit,
+	})
+	if err != nil {
+		return fmt.Errorf("could not get posts: %w", err)
+	}
+	t.posts = posts
+	t.postIdx = 0
+	t.scroll = 0
+	return nil
+}
+
+// readKey reads one key press, decoding the escape sequences of the arrow
+// and page keys.
+func (t *tui) readKey() (string, error) {
+	b, err := t.in.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	switch b {
+	case '\r', '\n':
+		return keyEnter, nil
+	case '\t':
+		return keyTab, nil
+	case 0x1b:
+		// A lone Escape has nothing buffered behind it
+		if t.in.Buffered() == 0 {
+			return "q", nil
+		}
+		seq := []byte{}
+		for t.in.Buffered() > 0 {
+			c, _ := t.in.ReadByte()
+			seq = append(seq, c)
+			if c >= 'A' && c <= 'Z' || c == '~' {
+				break
+			}
+		}
+		switch string(seq) {
+		case "[A", "OA":
+			return keyUp, nil
+		case "[B", "OB":
+			return keyDown, nil
+		case "[C", "OC":
+			return keyRight, nil
+		case "[D", "OD":
+			return keyLeft, nil
+		case "[5~":
+			return keyPageUp, nil
+		case "[6~":
+			return keyPageDown, nil
+		}
+		return "", nil
+	}
+	return string(b), nil
+}
+
+func (t *tui) pageSize() int {
+	_, height := t.size()
+	return max(height-4, 1)
+}
+
+func (t *tui) size() (int, int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 80, 24
+	}
+	return width, height
+}
+
+// draw repaints the whole screen: three panes side by side with a help
+// line at the bottom.
+func (t *tui) draw() {
+	width, height := t.size()
+	width = max(width, 1)
+	bodyHeight := max(height-2, 1)
+
+	var panes [][]string
+	if width < minFeedWidth+minPostWidth+minReaderWidth+2 {
+		switch t.focus {
+		case paneFeeds:
+			panes = append(panes, t.feedPane(width, bodyHeight))
+		case panePosts:
+			panes = append(panes, t.postPane(width, bodyHeight))
+		case paneReader:
+			panes = append(panes, t.readerPane(width, bodyHeight))
+		}
+	} else {
+		feedWidth := max(width/5, minFeedWidth)
+		postWidth := max(width*2/5, minPostWidth)
+		readerWidth := width - feedWidth - postWidth - 2
+		panes = append(panes,
+			t.feedPane(feedWidth, bodyHeight),
+			t.postPane(postWidth, bodyHeight),
+			t.readerPane(readerWidth, bodyHeight),
+		)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\x1b[H\x1b[2J")
+	sb.WriteString(t.header(width))
+	sb.WriteString("\r\n")
+	for i := range bodyHeight {
+		for j, lines := range panes {
+			if j > 0 {
+				sb.WriteString("│")
+			}
+			sb.WriteString(lines[i])
+		}
+		sb.WriteString("\r\n")
+	}
+	footer := "Tab/←→ switch pane  ↑↓/jk move  Enter open  o browser  m mark read  q quit"
+	if t.status != "" {
+		footer = t.status
+	}
+	sb.WriteString("\x1b[7m" + fit(footer, width) + "\x1b[0m")
+	fmt.Print(sb.String())
+}
+
+func (t *tui) header(width int) string {
+	title := fmt.Sprintf(" gator — %s — %s", t.user.Name, t.feeds[t.feedIdx].Name)
+	return "\x1b[1m" + fit(title, width) + "\x1b[0m"
+}
+
+func (t *tui) feedPane(width, height int) []string {
+	items := make([]string, len(t.feeds))
+	for i, f := range t.feeds {
+		label := f.Name
+		if f.Unread > 0 {
+			label = fmt.Sprintf("%s (%d)", f.Name, f.Unread)
+		}
+		items[i] = label
+	}
+	return listPane(items, t.feedIdx, t.focus == paneFeeds, width, height)
+}
+
+func (t *tui) postPane(width, height int) []string {
+	if len(t.posts) == 0 {
+		return listPane([]string{"No posts"}, -1, false, width, height)
+	}
+	items := make([]string, len(t.posts))
+	for i, p := range t.posts {
+		// * unread, + seen this session, blank once read
+		marker := "*"
+		if p.Read {
+			marker = " "
+		} else if t.seen[p.ID] {
+			marker = "+"
+		}
+		items[i] = fmt.Sprintf("%s %s %s", marker, p.SortTime.Format("Jan _2"), p.Title)
+	}
+	return listPane(items, t.postIdx, t.focus == panePosts, width, height)
+}
+
+func (t *tui) readerPane(width, height int) []string {
+	post, ok := t.selectedPost()
+	if !ok {
+		return listPane(nil, -1, false, width, height)
+	}
+
+	var lines []string
+	lines = append(lines, wrapText(post.Title, width)...)
+	lines = append(lines, fit(post.FeedName+" · "+post.SortTime.Format("Mon Jan _2 2006 15:04"), width))
+	lines = append(lines, fit(post.Url, width))
+	if post.Author.Valid {
+		lines = append(lines, fit("by "+post.Author.String, width))
+	}
+	lines = append(lines, "")
+	lines = append(lines, wrapText(htmltext.Render(postBody(post.Content, post.Description)), width)...)
+
+	t.scroll = clamp(t.scroll, 0, max(len(lines)-height, 0))
+	out := make([]string, height)
+	for i := range height {
+		line := ""
+		if t.scroll+i < len(lines) {
+			line = lines[t.scroll+i]
+		}
+		out[i] = fit(line, width)
+	}
+	return out
+}
+
+// listPane renders a scrolling list, keeping the selected item visible and
+// highlighting it when the pane has focus.
+func listPane(items []string, selected int, focused bool, width, height int) []string {
+	offset := 0
+	if selected >= height {
+		offset = selected - height + 1
+	}
+	out := make([]string, height)
+	for i := range height {
+		idx := offset + i
+		if idx >= len(items) {
+			out[i] = fit("", width)
+			continue
+		}
+		line := fit(items[idx], width)
+		switch {
+		case idx == selected && focused:
+			line = "\x1b[7m" + line + "\x1b[0m"
+		case idx == selected:
+			line = "\x1b[1m" + line + "\x1b[0m"
+		}
+		out[i] = line
+	}
+	return out
+}
+
+// wrapText breaks text into lines of at most width columns, keeping the
+// paragraph breaks and indentation of the input.
+func wrapText(text string, width int) []string {
+	width = max(width, 1)
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		paragraph = printable(paragraph)
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		// Indented lines, like nested list items, wrap at their indentation
+		indent := paragraph[:len(paragraph)-len(strings.TrimLeft(paragraph, " "))]
+		if len(indent) > width/2 {
+			indent = ""
+		}
+		for _, line := range wrapWords(words, width-len(indent)) {
+			lines = append(lines, indent+line)
+		}
+	}
+	return lines
+}
+
+func wrapWords(words []string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range words {
+		// Hard-break words that don't fit on a line of their own
+		for textWidth(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			head, tail := cutWidth(word, width)
+			if head == "" {
+				// A wide rune doesn't fit in a single column, let it overflow
+				_, size := utf8.DecodeRuneInString(word)
+				head, tail = word[:size], word[size:]
+			}
+			lines = append(lines, head)
+			word = tail
+		}
+		switch {
+		case line == "":
+			line = word
+		case textWidth(line)+1+textWidth(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fit pads or truncates s to exactly width terminal columns.
+func fit(s string, width int) string {
+	s = printable(s)
+	w := textWidth(s)
+	if w > width {
+		if width <= 1 {
+			head, _ := cutWidth(s, width)
+			return head + strings.Repeat(" ", width-textWidth(head))
+		}
+		head, _ := cutWidth(s, width-1)
+		return head + "…" + strings.Repeat(" ", width-1-textWidth(head))
+	}
+	return s + strings.Repeat(" ", width-w)
+}
+
+// printable is htmltext.Printable for a single line of a pane: tabs
+// become spaces, since they'd throw off the width, and line breaks go too.
+func printable(s string) string {
+	s = strings.ReplaceAll(htmltext.Printable(s), "\t", " ")
+	return strings.ReplaceAll(s, "\n", "")
+}
+
+// textWidth returns the number of terminal columns s takes up.
+func textWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		w += runeWidth(r)
+	}
+	return w
+}
+
+// cutWidth splits s after as many runes as fit in width columns.
+func cutWidth(s string, width int) (string, string) {
+	w := 0
+	for i, r := range s {
+		rw := runeWidth(r)
+		if w+rw > width {
+			return s[:i], s[i:]
+		}
+		w += rw
+	}
+	return s, ""
+}
+
+// runeWidth returns the number of columns a terminal uses for r: none for
+// combining marks and format characters, two for East Asian wide
+// characters and emoji, and one for the rest.
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	_, wide := slices.BinarySearchFunc(wideRunes, r, func(rr [2]rune, r rune) int {
+		switch {
+		case rr[1] < r:
+			return -1
+		case rr[0] > r:
+			return 1
+		}
+		return 0
+	})
+	if wide {
+		return 2
+	}
+	return 1
+}
+
+// wideRunes are the ranges of runes terminals draw two columns wide: the
+// wide and fullwidth characters of Unicode's East Asian Width property and
+// emoji with emoji presentation, sorted for binary search.
+var wideRunes = [][2]rune{
+	{0x1100, 0x115f},
+	{0x231a, 0x231b},
+	{0x2329, 0x232a},
+	{0x23e9, 0x23ec},
+	{0x23f0, 0x23f0},
+	{0x23f3, 0x23f3},
+	{0x25fd, 0x25fe},
+	{0x2614, 0x2615},
+	{0x2648, 0x2653},
+	{0x267f, 0x267f},
+	{0x2693, 0x2693},
+	{0x26a1, 0x26a1},
+	{0x26aa, 0x26ab},
+	{0x26bd, 0x26be},
+	{0x26c4, 0x26c5},
+	{0x26ce, 0x26ce},
+	{0x26d4, 0x26d4},
+	{0x26ea, 0x26ea},
+	{0x26f2, 0x26f3},
+	{0x26f5, 0x26f5},
+	{0x26fa, 0x26fa},
+	{0x26fd, 0x26fd},
+	{0x2705, 0x2705},
+	{0x270a, 0x270b},
+	{0x2728, 0x2728},
+	{0x274c, 0x274c},
+	{0x274e, 0x274e},
+	{0x2753, 0x2755},
+	{0x2757, 0x2757},
+	{0x2795, 0x2797},
+	{0x27b0, 0x27b0},
+	{0x27bf, 0x27bf},
+	{0x2b1b, 0x2b1c},
+	{0x2b50, 0x2b50},
+	{0x2b55, 0x2b55},
+	{0x2e80, 0x303e},
+	{0x3041, 0x33ff},
+	{0x3400, 0x4dbf},
+	{0x4e00, 0x9fff},
+	{0xa000, 0xa4cf},
+	{0xa960, 0xa97f},
+	{0xac00, 0xd7a3},
+	{0xf900, 0xfaff},
+	{0xfe10, 0xfe19},
+	{0xfe30, 0xfe6f},
+	{0xff00, 0xff60},
+	{0xffe0, 0xffe6},
+	{0x16fe0, 0x16fe4},
+	{0x17000, 0x18cff},
+	{0x1b000, 0x1b2ff},
+	{0x1f004, 0x1f004},
+	{0x1f0cf, 0x1f0cf},
+	{0x1f18e, 0x1f18e},
+	{0x1f191, 0x1f19a},
+	{0x1f200, 0x1f251},
+	{0x1f300, 0x1f320},
+	{0x1f32d, 0x1f335},
+	{0x1f337, 0x1f37c},
+	{0x1f37e, 0x1f393},
+	{0x1f3a0, 0x1f3ca},
+	{0x1f3cf, 0x1f3d3},
+	{0x1f3e0, 0x1f3f0},
+	{0x1f3f4, 0x1f3f4},
+	{0x1f3f8, 0x1f43e},
+	{0x1f440, 0x1f440},
+	{0x1f442, 0x1f4fc},
+	{0x1f4ff, 0x1f53d},
+	{0x1f54b, 0x1f54e},
+	{0x1f550, 0x1f567},
+	{0x1f57a, 0x1f57a},
+	{0x1f595, 0x1f596},
+	{0x1f5a4, 0x1f5a4},
+	{0x1f5fb, 0x1f64f},
+	{0x1f680, 0x1f6c5},
+	{0x1f6cc, 0x1f6cc},
+	{0x1f6d0, 0x1f6d2},
+	{0x1f6d5, 0x1f6d7},
+	{0x1f6dc, 0x1f6df},
+	{0x1f6eb, 0x1f6ec},
+	{0x1f6f4, 0x1f6fc},
+	{0x1f7e0, 0x1f7eb},
+	{0x1f7f0, 0x1f7f0},
+	{0x1f90c, 0x1f93a},
+	{0x1f93c, 0x1f945},
+	{0x1f947, 0x1f9ff},
+	{0x1fa70, 0x1faff},
+	{0x20000, 0x2fffd},
+	{0x30000, 0x3fffd},
+}
+
+func clamp(v, lo, hi int) int {
+	if hi < lo {
+		return lo
+	}
+	return min(max(v, lo), hi)
+}